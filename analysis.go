@@ -0,0 +1,376 @@
+package graphqlgin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// A field selected by an operation, with fragments flattened into its parent's
+// selections.
+type selectedField struct {
+	// AST node of the field
+	Field *ast.Field
+	// Schema definition of the field, nil for unknown and introspection fields
+	Definition *graphql.FieldDefinition
+	// Type the field is selected on
+	ParentType graphql.Type
+	// Response path of the field
+	Path []string
+	// Arguments of the field with variables substituted
+	Args map[string]interface{}
+	// Sub-selections of the field
+	Children []*selectedField
+}
+
+// Maximum number of selections expanded while collecting the fields of an
+// operation. Fragments spread repeatedly expand exponentially, so larger
+// operations are rejected instead of analyzed.
+const maxCollectedSelections = 10000
+
+// Error of operations expanding to more than `maxCollectedSelections` selections
+var errTooManySelections = fmt.Errorf("operation expands to more than %d selections", maxCollectedSelections)
+
+// Parses a GraphQL request string into an AST document.
+func parseQuery(requestString string) (*ast.Document, error) {
+	return parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{
+			Body: []byte(requestString),
+			Name: "GraphQL request",
+		}),
+	})
+}
+
+// Lazily parsed query document of a request, shared by the pre-execution checks
+// so the query string is parsed at most once.
+type queryDocument struct {
+	params   *GraphQLRequestParams
+	document *ast.Document
+	err      error
+	parsed   bool
+	fields   []*selectedField
+	// error of collecting the fields, and whether they were collected
+	fieldsErr error
+	collected bool
+	// request string the document was parsed from
+	source string
+	// set by `Validate` when the document is valid against the schema
+	valid bool
+	// maximum age of the result declared with `SetCacheMaxAge`
+	cacheMaxAge time.Duration
+	// set once the trusted or persisted query of the request is loaded
+	loaded bool
+	// error reply of loading the query, if any
+	loadReply map[string]interface{}
+}
+
+// Returns the parsed document of the request, parsing it again if the request
+// string changed since, i.e. when a trusted or persisted query was loaded.
+func (q *queryDocument) Document() (*ast.Document, error) {
+	if !q.parsed || q.source != q.params.RequestString {
+		q.document, q.err = parseQuery(q.params.RequestString)
+		q.source = q.params.RequestString
+		q.parsed = true
+		q.valid = false
+		q.fields = nil
+		q.collected = false
+	}
+	return q.document, q.err
+}
+
+// Returns true if the document of the current request string was validated.
+func (q *queryDocument) Valid() bool {
+	return q.valid && q.parsed && q.source == q.params.RequestString
+}
+
+// Validates the parsed document against `schema`, returning true if it is valid.
+func (q *queryDocument) Validate(schema *graphql.Schema) bool {
+	doc, err := q.Document()
+	if err != nil {
+		return false
+	}
+	q.valid = graphql.ValidateDocument(schema, doc, nil).IsValid
+	return q.valid
+}
+
+// Returns the parsed document and the operation selected by the request.
+func (q *queryDocument) Operation() (*ast.Document, *ast.OperationDefinition, error) {
+	doc, err := q.Document()
+	if err != nil {
+		return nil, nil, err
+	}
+	operation, err := selectOperation(doc, q.params.OperationName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, operation, nil
+}
+
+// Returns the tree of fields selected by the requested operation.
+func (q *queryDocument) Fields(schema *graphql.Schema) ([]*selectedField, error) {
+	if q.collected && q.source == q.params.RequestString {
+		return q.fields, q.fieldsErr
+	}
+	doc, operation, err := q.Operation()
+	if err != nil {
+		return nil, err
+	}
+	q.fields, q.fieldsErr = collectSelectedFields(schema, doc, operation, q.params.VariableValues)
+	q.collected = true
+	return q.fields, q.fieldsErr
+}
+
+// Returns true if the error of `Fields` is due to the size of the operation
+// rather than an invalid document, so the request must be rejected.
+func isTooManySelections(err error) bool {
+	return errors.Is(err, errTooManySelections)
+}
+
+// Returns true if any pre-execution check analyzing the selected fields is enabled.
+func (app *GraphQLApp) analyzesQueries() bool {
+	return app.DisableIntrospection ||
+		app.MaxQueryDepth > 0 ||
+		app.BlockDeprecatedFields ||
+		len(app.FeatureFields) > 0 ||
+		app.MaxEstimatedResultSize > 0 ||
+		app.CostFn != nil ||
+		app.MaxComplexity > 0
+}
+
+// Returns the operation of `doc` that would be executed for `operationName`.
+func selectOperation(doc *ast.Document, operationName string) (*ast.OperationDefinition, error) {
+	var selected *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" {
+			if selected != nil {
+				return nil, fmt.Errorf("must provide operation name if query contains multiple operations")
+			}
+			selected = operation
+		} else if operation.Name != nil && operation.Name.Value == operationName {
+			return operation, nil
+		}
+	}
+	if selected == nil {
+		if operationName != "" {
+			return nil, fmt.Errorf("unknown operation named %q", operationName)
+		}
+		return nil, fmt.Errorf("must provide an operation")
+	}
+	return selected, nil
+}
+
+// Sets the operation name of requests without one to the name of the single
+// operation of the document. Returns an error naming the operations if the
+// document has several, leaving other invalid documents to the execution.
+func inferOperationName(query *queryDocument) error {
+	doc, err := query.Document()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		if definition, ok := definition.(*ast.OperationDefinition); ok {
+			operation = definition
+			name := "<anonymous>"
+			if definition.Name != nil {
+				name = definition.Name.Value
+			}
+			names = append(names, name)
+		}
+	}
+	if len(names) > 1 {
+		return fmt.Errorf("document contains the operations %s, set operationName to the one to execute", strings.Join(names, ", "))
+	}
+	if operation != nil && operation.Name != nil {
+		query.params.OperationName = operation.Name.Value
+	}
+	return nil
+}
+
+// Returns the root type of the schema for the `operation`.
+func operationRootType(schema *graphql.Schema, operation *ast.OperationDefinition) graphql.Type {
+	switch operation.Operation {
+	case ast.OperationTypeMutation:
+		if mutation := schema.MutationType(); mutation != nil {
+			return mutation
+		}
+	case ast.OperationTypeSubscription:
+		if subscription := schema.SubscriptionType(); subscription != nil {
+			return subscription
+		}
+	default:
+		if query := schema.QueryType(); query != nil {
+			return query
+		}
+	}
+	return nil
+}
+
+// Converts an AST value into a plain go value, substituting variables.
+func astValue(value ast.Value, variables map[string]interface{}) interface{} {
+	switch value := value.(type) {
+	case *ast.Variable:
+		if value.Name == nil {
+			return nil
+		}
+		return variables[value.Name.Value]
+	case *ast.IntValue:
+		if i, err := strconv.Atoi(value.Value); err == nil {
+			return i
+		}
+		return nil
+	case *ast.FloatValue:
+		if f, err := strconv.ParseFloat(value.Value, 64); err == nil {
+			return f
+		}
+		return nil
+	case *ast.StringValue:
+		return value.Value
+	case *ast.BooleanValue:
+		return value.Value
+	case *ast.EnumValue:
+		return value.Value
+	case *ast.ListValue:
+		list := make([]interface{}, 0, len(value.Values))
+		for _, item := range value.Values {
+			list = append(list, astValue(item, variables))
+		}
+		return list
+	case *ast.ObjectValue:
+		object := make(map[string]interface{}, len(value.Fields))
+		for _, field := range value.Fields {
+			if field.Name != nil {
+				object[field.Name.Value] = astValue(field.Value, variables)
+			}
+		}
+		return object
+	}
+	return nil
+}
+
+// Returns the field definitions of composite type `t`.
+func typeFields(t graphql.Type) graphql.FieldDefinitionMap {
+	switch t := t.(type) {
+	case *graphql.Object:
+		return t.Fields()
+	case *graphql.Interface:
+		return t.Fields()
+	}
+	return nil
+}
+
+// Walks selection sets of an operation resolving fragments against the schema.
+type selectionCollector struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// number of selections expanded so far
+	selections int
+}
+
+func (sc *selectionCollector) collect(parentType graphql.Type, selectionSet *ast.SelectionSet, path []string, visiting map[string]bool) []*selectedField {
+	if selectionSet == nil {
+		return nil
+	}
+	var fields []*selectedField
+	for _, selection := range selectionSet.Selections {
+		// stop expanding once over budget, including spreads selecting no fields
+		if sc.selections++; sc.selections > maxCollectedSelections {
+			return fields
+		}
+		switch selection := selection.(type) {
+		case *ast.Field:
+			fields = append(fields, sc.collectField(parentType, selection, path, visiting))
+		case *ast.InlineFragment:
+			fragmentType := parentType
+			if selection.TypeCondition != nil && selection.TypeCondition.Name != nil {
+				fragmentType = sc.schema.Type(selection.TypeCondition.Name.Value)
+			}
+			fields = append(fields, sc.collect(fragmentType, selection.SelectionSet, path, visiting)...)
+		case *ast.FragmentSpread:
+			if selection.Name == nil || visiting[selection.Name.Value] {
+				// avoid cycles, validation will report them
+				continue
+			}
+			fragment, ok := sc.fragments[selection.Name.Value]
+			if !ok {
+				continue
+			}
+			fragmentType := parentType
+			if fragment.TypeCondition != nil && fragment.TypeCondition.Name != nil {
+				fragmentType = sc.schema.Type(fragment.TypeCondition.Name.Value)
+			}
+			visiting[selection.Name.Value] = true
+			fields = append(fields, sc.collect(fragmentType, fragment.SelectionSet, path, visiting)...)
+			delete(visiting, selection.Name.Value)
+		}
+	}
+	return fields
+}
+
+func (sc *selectionCollector) collectField(parentType graphql.Type, field *ast.Field, path []string, visiting map[string]bool) *selectedField {
+	name := ""
+	if field.Name != nil {
+		name = field.Name.Value
+	}
+	responseKey := name
+	if field.Alias != nil {
+		responseKey = field.Alias.Value
+	}
+	fieldPath := make([]string, len(path), len(path)+1)
+	copy(fieldPath, path)
+	fieldPath = append(fieldPath, responseKey)
+
+	selected := &selectedField{
+		Field:      field,
+		ParentType: parentType,
+		Path:       fieldPath,
+		Args:       map[string]interface{}{},
+	}
+	for _, argument := range field.Arguments {
+		if argument.Name != nil {
+			selected.Args[argument.Name.Value] = astValue(argument.Value, sc.variables)
+		}
+	}
+	selected.Definition = typeFields(parentType)[name]
+	var fieldType graphql.Type
+	if selected.Definition != nil {
+		fieldType, _ = graphql.GetNamed(selected.Definition.Type).(graphql.Type)
+	}
+	// keep the shape of introspection and unknown fields, so their selections
+	// count towards the depth and cost of the operation
+	selected.Children = sc.collect(fieldType, field.SelectionSet, fieldPath, visiting)
+	return selected
+}
+
+// Collects the tree of fields selected by `operation`. Returns
+// `errTooManySelections` if it expands to too many selections.
+func collectSelectedFields(schema *graphql.Schema, doc *ast.Document, operation *ast.OperationDefinition, variables map[string]interface{}) ([]*selectedField, error) {
+	collector := &selectionCollector{
+		schema:    schema,
+		fragments: map[string]*ast.FragmentDefinition{},
+		variables: variables,
+	}
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok && fragment.Name != nil {
+			collector.fragments[fragment.Name.Value] = fragment
+		}
+	}
+	fields := collector.collect(operationRootType(schema, operation), operation.SelectionSet, nil, map[string]bool{})
+	if collector.selections > maxCollectedSelections {
+		return nil, errTooManySelections
+	}
+	return fields, nil
+}
@@ -0,0 +1,129 @@
+package graphqlgin
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Extension key of the Apollo tracing data added with `TracingEnabled`
+const ApolloTracingExtension = "tracing"
+
+// Version of the Apollo tracing format
+const apolloTracingVersion = 1
+
+// Key of the Apollo tracing collector in the resolver context
+const apolloTracingKey contextKey = "ApolloTracing"
+
+// Timing of a single resolver in the Apollo tracing format
+type apolloTracingResolver struct {
+	Path        []interface{} `json:"path"`
+	ParentType  string        `json:"parentType"`
+	FieldName   string        `json:"fieldName"`
+	ReturnType  string        `json:"returnType"`
+	StartOffset int64         `json:"startOffset"`
+	Duration    int64         `json:"duration"`
+}
+
+// Collects the resolver timings of an operation
+type apolloTracing struct {
+	started time.Time
+
+	mu        sync.Mutex
+	resolvers []apolloTracingResolver
+}
+
+// Returns the tracing data of the operation in the Apollo tracing format.
+func (tracing *apolloTracing) extension(ended time.Time) map[string]interface{} {
+	tracing.mu.Lock()
+	defer tracing.mu.Unlock()
+	resolvers := tracing.resolvers
+	if resolvers == nil {
+		resolvers = []apolloTracingResolver{}
+	}
+	return map[string]interface{}{
+		"version":   apolloTracingVersion,
+		"startTime": tracing.started.UTC().Format(time.RFC3339Nano),
+		"endTime":   ended.UTC().Format(time.RFC3339Nano),
+		"duration":  ended.Sub(tracing.started).Nanoseconds(),
+		"execution": map[string]interface{}{
+			"resolvers": resolvers,
+		},
+	}
+}
+
+// Schema extension recording the timing of each resolved field of operations
+// traced with `TracingEnabled`, so the resolvers of the schema are not wrapped.
+type apolloTracingExtension struct{}
+
+func (apolloTracingExtension) Init(ctx context.Context, p *graphql.Params) context.Context {
+	return ctx
+}
+
+func (apolloTracingExtension) Name() string {
+	return "ApolloTracing"
+}
+
+func (apolloTracingExtension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(error) {}
+}
+
+func (apolloTracingExtension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func([]gqlerrors.FormattedError) {}
+}
+
+func (apolloTracingExtension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(*graphql.Result) {}
+}
+
+func (apolloTracingExtension) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	tracing, _ := ctx.Value(apolloTracingKey).(*apolloTracing)
+	if tracing == nil || strings.HasPrefix(info.ParentType.Name(), "__") || strings.HasPrefix(info.FieldName, "__") {
+		// introspection fields are resolved by graphql-go itself
+		return ctx, func(interface{}, error) {}
+	}
+	started := time.Now()
+	return ctx, func(interface{}, error) {
+		resolver := apolloTracingResolver{
+			ParentType:  info.ParentType.Name(),
+			FieldName:   info.FieldName,
+			ReturnType:  info.ReturnType.String(),
+			StartOffset: started.Sub(tracing.started).Nanoseconds(),
+			Duration:    time.Since(started).Nanoseconds(),
+		}
+		if info.Path != nil {
+			resolver.Path = info.Path.AsArray()
+		}
+		tracing.mu.Lock()
+		tracing.resolvers = append(tracing.resolvers, resolver)
+		tracing.mu.Unlock()
+	}
+}
+
+func (apolloTracingExtension) HasResult() bool {
+	return false
+}
+
+func (apolloTracingExtension) GetResult(ctx context.Context) interface{} {
+	return nil
+}
+
+// Returns a copy of the schema with the Apollo tracing extension, created once.
+func (app *GraphQLApp) tracedSchema() graphql.Schema {
+	app.apolloTracingOnce.Do(func() {
+		app.apolloTracingSchema = app.executableSchema()
+		app.apolloTracingSchema.AddExtensions(apolloTracingExtension{})
+	})
+	return app.apolloTracingSchema
+}
+
+// Starts collecting the resolver timings of an operation executed with
+// `tracedSchema`. Returns the context carrying the collector.
+func (app *GraphQLApp) startApolloTracing(ctx context.Context) (context.Context, *apolloTracing) {
+	tracing := &apolloTracing{started: time.Now()}
+	return context.WithValue(ctx, apolloTracingKey, tracing), tracing
+}
@@ -0,0 +1,126 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+type apolloTracingResponse struct {
+	Extensions struct {
+		Tracing *struct {
+			Version   int    `json:"version"`
+			StartTime string `json:"startTime"`
+			EndTime   string `json:"endTime"`
+			Duration  int64  `json:"duration"`
+			Execution struct {
+				Resolvers []apolloTracingResolver `json:"resolvers"`
+			} `json:"execution"`
+		} `json:"tracing"`
+	} `json:"extensions"`
+}
+
+func apolloTracingSchema() graphql.Schema {
+	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "world", nil
+					},
+				},
+			},
+		}),
+	})
+	return schema
+}
+
+func TestApolloTracingPOST(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		app := New(apolloTracingSchema())
+		app.TracingEnabled = enabled
+		router := setupRouter(app)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		var res apolloTracingResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+			t.Errorf("Response unmarshal failed. Err: %v", err)
+		}
+		tracing := res.Extensions.Tracing
+		if !enabled {
+			if tracing != nil {
+				t.Errorf("Tracing extension found while disabled. Found %s", recorder.Body.String())
+			}
+			continue
+		}
+		if tracing == nil {
+			t.Fatalf("Tracing extension missing. Found %s", recorder.Body.String())
+		}
+		if tracing.Version != 1 || tracing.StartTime == "" || tracing.EndTime == "" || tracing.Duration <= 0 {
+			t.Errorf("Tracing incorrect. Found %s", recorder.Body.String())
+		}
+		if len(tracing.Execution.Resolvers) != 1 {
+			t.Fatalf("Traced resolvers incorrect. Found %s", recorder.Body.String())
+		}
+		resolver := tracing.Execution.Resolvers[0]
+		if len(resolver.Path) != 1 || resolver.Path[0] != "hello" ||
+			resolver.ParentType != "Query" || resolver.FieldName != "hello" || resolver.ReturnType != "String" {
+			t.Errorf("Traced resolver incorrect. Found %+v", resolver)
+		}
+		if resolver.StartOffset < 0 || resolver.Duration < 0 || resolver.StartOffset+resolver.Duration > tracing.Duration {
+			t.Errorf("Traced resolver timing incorrect. Found %+v in %d", resolver, tracing.Duration)
+		}
+	}
+}
+
+func TestApolloTracingSharedSchemaPOST(t *testing.T) {
+	sharedSchema := apolloTracingSchema()
+	traced := New(sharedSchema)
+	traced.TracingEnabled = true
+	plain := New(sharedSchema)
+
+	var wg sync.WaitGroup
+	for _, app := range []*GraphQLApp{traced, plain, traced, plain} {
+		wg.Add(1)
+		go func(app *GraphQLApp) {
+			defer wg.Done()
+			router := setupRouter(app)
+
+			recorder := httptest.NewRecorder()
+			request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+			request.Header.Add("Content-Type", "application/json")
+
+			router.ServeHTTP(recorder, request)
+
+			var res apolloTracingResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+				t.Errorf("Response unmarshal failed. Err: %v", err)
+			}
+			if enabled := res.Extensions.Tracing != nil; enabled != app.TracingEnabled {
+				t.Errorf("Tracing extension incorrect. Found %s", recorder.Body.String())
+			}
+		}(app)
+	}
+	wg.Wait()
+
+	// the untraced app runs the original resolvers
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+	setupRouter(plain).ServeHTTP(recorder, request)
+	if expected := `{"data":{"hello":"world"}}`; recorder.Body.String() != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+}
@@ -0,0 +1,150 @@
+package graphqlgin
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Error messages and codes of automatic persisted queries, as expected by clients
+const (
+	PersistedQueryNotFound         = "PersistedQueryNotFound"
+	PersistedQueryNotFoundCode     = "PERSISTED_QUERY_NOT_FOUND"
+	PersistedQueryNotSupported     = "PersistedQueryNotSupported"
+	PersistedQueryNotSupportedCode = "PERSISTED_QUERY_NOT_SUPPORTED"
+	PersistedQueryHashMismatch     = "provided sha does not match query"
+	PersistedQueryHashMismatchCode = "INVALID_PERSISTED_QUERY"
+)
+
+// Keys of the persisted query request extension
+const (
+	persistedQueryExtension  = "persistedQuery"
+	persistedQueryHashKey    = "sha256Hash"
+	persistedQueryVersionKey = "version"
+)
+
+// Supported version of the persisted query protocol
+const persistedQueryVersion = 1
+
+// Default size of `NewLRUPersistedQueryCache`
+const persistedQueryCacheSize = 1000
+
+// Storage of automatic persisted queries keyed by the SHA-256 hex digest of the query
+type PersistedQueryCache interface {
+	// Returns the query of `hash`, if it is known
+	Get(hash string) (string, bool)
+	// Stores the query of `hash`
+	Set(hash string, query string)
+}
+
+// In memory `PersistedQueryCache` evicting the least recently used queries
+type lruPersistedQueryCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruPersistedQuery struct {
+	hash  string
+	query string
+}
+
+// Returns an in memory `PersistedQueryCache` holding up to `size` queries. A
+// non positive `size` defaults to 1000.
+func NewLRUPersistedQueryCache(size int) PersistedQueryCache {
+	if size <= 0 {
+		size = persistedQueryCacheSize
+	}
+	return &lruPersistedQueryCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func (cache *lruPersistedQueryCache) Get(hash string) (string, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	element, ok := cache.entries[hash]
+	if !ok {
+		return "", false
+	}
+	cache.order.MoveToFront(element)
+	return element.Value.(*lruPersistedQuery).query, true
+}
+
+func (cache *lruPersistedQueryCache) Set(hash string, query string) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if element, ok := cache.entries[hash]; ok {
+		element.Value.(*lruPersistedQuery).query = query
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.entries[hash] = cache.order.PushFront(&lruPersistedQuery{hash: hash, query: query})
+	for cache.order.Len() > cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*lruPersistedQuery).hash)
+	}
+}
+
+// Error of resolving an automatic persisted query
+type persistedQueryError struct {
+	message string
+	code    string
+}
+
+// Returns the reply of the error, with the exact message clients look for.
+func (e *persistedQueryError) reply() map[string]interface{} {
+	return map[string]interface{}{
+		"errors": []map[string]interface{}{
+			{
+				"message":    e.message,
+				"extensions": map[string]interface{}{"code": e.code},
+			},
+		},
+	}
+}
+
+// Returns the SHA-256 hex digest of a query string.
+func persistedQueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// Resolves the automatic persisted query of the request, if any. A request with
+// only the hash gets the query from the `PersistedQueryCache`, while a request
+// with both the hash and the query stores it once the hash is verified.
+func (app *GraphQLApp) loadPersistedQuery(params *GraphQLRequestParams) *persistedQueryError {
+	persistedQuery, ok := params.Extensions[persistedQueryExtension].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if app.PersistedQueryCache == nil {
+		if params.RequestString != "" {
+			// nothing to persist, run the query as usual
+			return nil
+		}
+		return &persistedQueryError{PersistedQueryNotSupported, PersistedQueryNotSupportedCode}
+	}
+	if version, _ := persistedQuery[persistedQueryVersionKey].(float64); version != persistedQueryVersion {
+		return &persistedQueryError{PersistedQueryNotSupported, PersistedQueryNotSupportedCode}
+	}
+	hash, _ := persistedQuery[persistedQueryHashKey].(string)
+	if params.RequestString == "" {
+		query, ok := app.PersistedQueryCache.Get(hash)
+		if !ok {
+			return &persistedQueryError{PersistedQueryNotFound, PersistedQueryNotFoundCode}
+		}
+		params.RequestString = query
+		return nil
+	}
+	if persistedQueryHash(params.RequestString) != hash {
+		return &persistedQueryError{PersistedQueryHashMismatch, PersistedQueryHashMismatchCode}
+	}
+	app.PersistedQueryCache.Set(hash, params.RequestString)
+	return nil
+}
@@ -0,0 +1,129 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func persistedQueryRequest(router *gin.Engine, query string, hash string) string {
+	body := fmt.Sprintf(`{"query": %q, "extensions": {"persistedQuery": {"version": 1, "sha256Hash": %q}}}`, query, hash)
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+	return recorder.Body.String()
+}
+
+func TestPersistedQueriesPOST(t *testing.T) {
+	app := New(schema)
+	app.PersistedQueryCache = NewLRUPersistedQueryCache(10)
+	router := setupRouter(app)
+
+	query := "{ hello }"
+	hash := persistedQueryHash(query)
+
+	notFound := `{"errors":[{"extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"},"message":"PersistedQueryNotFound"}]}`
+	if body := persistedQueryRequest(router, "", hash); body != notFound {
+		t.Errorf("Response of unknown hash incorrect. Found %s, expected %s", body, notFound)
+	}
+	if body := persistedQueryRequest(router, query, hash); body != `{"data":{"hello":"world"}}` {
+		t.Errorf("Response of query with hash incorrect. Found %s", body)
+	}
+	if body := persistedQueryRequest(router, "", hash); body != `{"data":{"hello":"world"}}` {
+		t.Errorf("Response of persisted hash incorrect. Found %s", body)
+	}
+}
+
+func TestPersistedQueryHashMismatchPOST(t *testing.T) {
+	app := New(schema)
+	app.PersistedQueryCache = NewLRUPersistedQueryCache(10)
+	router := setupRouter(app)
+
+	hash := persistedQueryHash("{ hello }")
+	mismatch := `{"errors":[{"extensions":{"code":"INVALID_PERSISTED_QUERY"},"message":"provided sha does not match query"}]}`
+	if body := persistedQueryRequest(router, "{ context }", hash); body != mismatch {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, mismatch)
+	}
+	if _, ok := app.PersistedQueryCache.Get(hash); ok {
+		t.Errorf("Mismatching query was cached")
+	}
+}
+
+func TestPersistedQueriesNotSupportedPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	hash := persistedQueryHash("{ hello }")
+	notSupported := `{"errors":[{"extensions":{"code":"PERSISTED_QUERY_NOT_SUPPORTED"},"message":"PersistedQueryNotSupported"}]}`
+	if body := persistedQueryRequest(router, "", hash); body != notSupported {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, notSupported)
+	}
+	if body := persistedQueryRequest(router, "{ hello }", hash); body != `{"data":{"hello":"world"}}` {
+		t.Errorf("Response of query with hash incorrect. Found %s", body)
+	}
+}
+
+func TestLRUPersistedQueryCache(t *testing.T) {
+	cache := NewLRUPersistedQueryCache(2)
+	cache.Set("a", "{ a }")
+	cache.Set("b", "{ b }")
+	cache.Get("a")
+	cache.Set("c", "{ c }")
+
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Least recently used query not evicted")
+	}
+	for _, hash := range []string{"a", "c"} {
+		if _, ok := cache.Get(hash); !ok {
+			t.Errorf("Query %s evicted", hash)
+		}
+	}
+}
+
+func TestPersistedQueriesGET(t *testing.T) {
+	app := New(schema)
+	app.PersistedQueryCache = NewLRUPersistedQueryCache(10)
+	router := setupRouter(app)
+
+	query := "{ hello }"
+	hash := persistedQueryHash(query)
+	extensions := fmt.Sprintf(`{"persistedQuery":{"version":1,"sha256Hash":%q}}`, hash)
+	persistedGET := func(params url.Values) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/?"+params.Encode(), nil)
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	notFound := `{"errors":[{"extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"},"message":"PersistedQueryNotFound"}]}`
+	if body := persistedGET(url.Values{"extensions": {extensions}}).Body.String(); body != notFound {
+		t.Errorf("Response of unknown hash incorrect. Found %s, expected %s", body, notFound)
+	}
+	if body := persistedGET(url.Values{"query": {query}, "extensions": {extensions}}).Body.String(); body != `{"data":{"hello":"world"}}` {
+		t.Errorf("Response of query with hash incorrect. Found %s", body)
+	}
+	if body := persistedGET(url.Values{"extensions": {extensions}}).Body.String(); body != `{"data":{"hello":"world"}}` {
+		t.Errorf("Response of persisted hash incorrect. Found %s", body)
+	}
+
+	// resolved mutations are still not allowed over GET
+	mutation := "mutation ($file: Upload!) { singleUpload(file: $file) { size } }"
+	persistedQueryRequest(router, mutation, persistedQueryHash(mutation))
+	mutationExtensions := fmt.Sprintf(`{"persistedQuery":{"version":1,"sha256Hash":%q}}`, persistedQueryHash(mutation))
+	if code := persistedGET(url.Values{"extensions": {mutationExtensions}}).Code; code != http.StatusMethodNotAllowed {
+		t.Errorf("Status code of persisted mutation incorrect. Found %d, expected %d", code, http.StatusMethodNotAllowed)
+	}
+
+	for _, malformed := range []string{`{"persistedQuery":`, `[1]`, `"hash"`} {
+		if code := persistedGET(url.Values{"extensions": {malformed}}).Code; code != http.StatusBadRequest {
+			t.Errorf("Status code of extensions %s incorrect. Found %d, expected %d", malformed, code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reads a batch of operations sent as a JSON array body. Returns false when the
+// request is not a batch, in which case the body is left intact for binding.
+func readBatch(c *gin.Context) ([]GraphQLRequestParams, bool, error) {
+	if c.Request.Method != "POST" || c.ContentType() != gin.MIMEJSON || c.Request.Body == nil {
+		return nil, false, nil
+	}
+	body, err := ioutil.ReadAll(c.Request.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	// restore the body for binding single operations
+	c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if trimmed := bytes.TrimSpace(body); len(trimmed) == 0 || trimmed[0] != '[' {
+		return nil, false, nil
+	}
+
+	var batch []GraphQLRequestParams
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, true, err
+	}
+	return batch, true, nil
+}
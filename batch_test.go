@@ -0,0 +1,102 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBatchPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	batch := []map[string]interface{}{
+		{
+			"query":         "query hello { hello }",
+			"operationName": "hello",
+		},
+		{
+			"query":         "query broken { unknownField }",
+			"operationName": "broken",
+		},
+		{
+			"query":         "query double ($value: Int) { double(value: $value) }",
+			"operationName": "double",
+			"variables": map[string]interface{}{
+				"value": 5,
+			},
+		},
+	}
+	batchBody, _ := json.Marshal(batch)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(batchBody))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Request failed. Code: %d", recorder.Code)
+	}
+	var res []struct {
+		Data   map[string]interface{}   `json:"data"`
+		Errors []map[string]interface{} `json:"errors"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Response unmarshal failed. Err: %v", err)
+	}
+	if len(res) != 3 {
+		t.Fatalf("Results count incorrect. Found %d, expected %d", len(res), 3)
+	}
+	if res[0].Data["hello"] != "world" {
+		t.Errorf("First result incorrect. Found %v", res[0].Data)
+	}
+	if len(res[1].Errors) == 0 {
+		t.Errorf("Second result has no errors")
+	}
+	if res[2].Data["double"] != float64(10) {
+		t.Errorf("Third result incorrect. Found %v", res[2].Data)
+	}
+}
+
+func TestBatchResultOperationNamePOST(t *testing.T) {
+	app := New(schema)
+	app.ResultOperationName = true
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`[
+		{"query": "query first { hello }"},
+		{"query": "query a { hello } query second { hello }", "operationName": "second"}
+	]`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	var res []operationNameResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Response unmarshal failed. Err: %v", err)
+	}
+	for i, expected := range []string{"first", "second"} {
+		if name := res[i].Extensions[OperationNameExtension]; name != expected {
+			t.Errorf("Operation name incorrect. Found %v, expected %v", name, expected)
+		}
+	}
+}
+
+func TestBatchInvalidPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`[{"query": `))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,114 @@
+package graphqlgin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Key of the cache policy of the operation in the resolver context
+const cachePolicyKey contextKey = "CachePolicy"
+
+// Maximum age of the response of an operation, lowered by its resolvers
+type cachePolicy struct {
+	mu     sync.Mutex
+	maxAge time.Duration
+	set    bool
+}
+
+func (policy *cachePolicy) lower(maxAge time.Duration) {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	if !policy.set || maxAge < policy.maxAge {
+		policy.maxAge = maxAge
+		policy.set = true
+	}
+}
+
+func (policy *cachePolicy) get() time.Duration {
+	policy.mu.Lock()
+	defer policy.mu.Unlock()
+	return policy.maxAge
+}
+
+// Declares how long the response of the current operation may be cached. The
+// lowest age declared by the resolvers of an operation is used. Does nothing
+// unless `CacheControl` is enabled.
+func SetCacheMaxAge(ctx context.Context, maxAge time.Duration) {
+	if policy, ok := ctx.Value(cachePolicyKey).(*cachePolicy); ok {
+		policy.lower(maxAge)
+	}
+}
+
+// Returns the context carrying a new cache policy starting at
+// `DefaultCacheMaxAge`.
+func (app *GraphQLApp) startCachePolicy(ctx context.Context) (context.Context, *cachePolicy) {
+	policy := &cachePolicy{}
+	if app.DefaultCacheMaxAge > 0 {
+		policy.lower(app.DefaultCacheMaxAge)
+	}
+	return context.WithValue(ctx, cachePolicyKey, policy), policy
+}
+
+// Checks if the `If-None-Match` header matches the weak `etag`.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Sets the caching headers of the response of a single operation. Successful
+// queries get an ETag, and are cached privately, or publicly with
+// `PublicCache`, for their maximum age or revalidated. Everything else is not
+// stored. Returns true if a 304 reply was sent because
+// the client has the current result.
+func (app *GraphQLApp) cacheResponse(c *gin.Context, query *queryDocument, status int, reply interface{}) bool {
+	result, ok := reply.(*graphql.Result)
+	if !ok || status != http.StatusOK || len(result.Errors) > 0 || requestOperationType(query) != ast.OperationTypeQuery {
+		c.Header("Cache-Control", "no-store")
+		return false
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		c.Header("Cache-Control", "no-store")
+		return false
+	}
+	sum := sha256.Sum256(body)
+	// weak, as the compressed responses are not byte for byte identical
+	etag := `W/"` + hex.EncodeToString(sum[:]) + `"`
+	// responses may depend on the user, so only private caches may store them
+	// unless marked public
+	scope := "private"
+	if app.PublicCache {
+		scope = "public"
+	}
+	if maxAge := int(query.cacheMaxAge / time.Second); maxAge > 0 {
+		c.Header("Cache-Control", fmt.Sprintf("%s, max-age=%d", scope, maxAge))
+	} else {
+		c.Header("Cache-Control", scope+", no-cache")
+	}
+	// the content type of the response is negotiated
+	c.Writer.Header().Add("Vary", "Accept")
+	c.Header("ETag", etag)
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Set(replyKey, reply)
+		c.Status(http.StatusNotModified)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+var cacheSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+			"news": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					SetCacheMaxAge(p.Context, time.Minute)
+					return "headline", nil
+				},
+			},
+			"ticker": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					SetCacheMaxAge(p.Context, 5*time.Second)
+					return "price", nil
+				},
+			},
+		},
+	}),
+	Mutation: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"touch": helloQuery,
+		},
+	}),
+})
+
+func cacheGETRequest(router *gin.Engine, query string, ifNoneMatch string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/?query="+url.QueryEscape(query), nil)
+	if ifNoneMatch != "" {
+		request.Header.Add("If-None-Match", ifNoneMatch)
+	}
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestCacheControlGET(t *testing.T) {
+	app := New(cacheSchema)
+	app.CacheControl = true
+	router := setupRouter(app)
+
+	for _, test := range []struct {
+		query        string
+		cacheControl string
+	}{
+		{"{ news }", "private, max-age=60"},
+		{"{ news ticker }", "private, max-age=5"},
+		{"{ hello }", "private, no-cache"},
+		{"{ unknown }", "no-store"},
+	} {
+		recorder := cacheGETRequest(router, test.query, "")
+		if header := recorder.Header().Get("Cache-Control"); header != test.cacheControl {
+			t.Errorf("Cache-Control of %s incorrect. Found %s, expected %s", test.query, header, test.cacheControl)
+		}
+	}
+
+	recorder := cacheGETRequest(router, "{ news }", "")
+	if vary := recorder.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("Vary header incorrect. Found %s, expected Accept", vary)
+	}
+	etag := recorder.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("ETag missing")
+	}
+	recorder = cacheGETRequest(router, "{ news }", etag)
+	if recorder.Code != http.StatusNotModified {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusNotModified)
+	}
+	if body := recorder.Body.String(); body != "" {
+		t.Errorf("Response of 304 not empty. Found %s", body)
+	}
+	recorder = cacheGETRequest(router, "{ ticker }", etag)
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Status code of changed result incorrect. Found %d, expected %d", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestCacheControlPublicGET(t *testing.T) {
+	app := New(cacheSchema)
+	app.CacheControl = true
+	app.PublicCache = true
+	router := setupRouter(app)
+
+	recorder := cacheGETRequest(router, "{ news }", "")
+	if header := recorder.Header().Get("Cache-Control"); header != "public, max-age=60" {
+		t.Errorf("Cache-Control incorrect. Found %s, expected %s", header, "public, max-age=60")
+	}
+}
+
+func TestCacheControlMutationPOST(t *testing.T) {
+	app := New(cacheSchema)
+	app.CacheControl = true
+	app.DefaultCacheMaxAge = time.Minute
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "mutation { touch }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if header := recorder.Header().Get("Cache-Control"); header != "no-store" {
+		t.Errorf("Cache-Control incorrect. Found %s, expected %s", header, "no-store")
+	}
+	if header := recorder.Header().Get("ETag"); header != "" {
+		t.Errorf("ETag of mutation found. Found %s", header)
+	}
+}
+
+func TestCacheControlCompressedGET(t *testing.T) {
+	app := New(cacheSchema)
+	app.CacheControl = true
+	app.Compress = true
+	router := setupRouter(app)
+
+	etag := cacheGETRequest(router, "{ news }", "").Header().Get("ETag")
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/?query="+url.QueryEscape("{ news }"), nil)
+	request.Header.Add("If-None-Match", etag)
+	request.Header.Add("Accept-Encoding", "gzip")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotModified {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusNotModified)
+	}
+	if recorder.Body.Len() != 0 || recorder.Header().Get("Content-Encoding") != "" {
+		t.Errorf("Response of 304 compressed. Found %q with encoding %q", recorder.Body.String(), recorder.Header().Get("Content-Encoding"))
+	}
+}
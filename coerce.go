@@ -0,0 +1,98 @@
+package graphqlgin
+
+import (
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Returns the type of `schema` declared by the AST type `t`, or nil if it is
+// not defined.
+func astInputType(schema *graphql.Schema, t ast.Type) graphql.Type {
+	switch t := t.(type) {
+	case *ast.NonNull:
+		if ofType := astInputType(schema, t.Type); ofType != nil {
+			return graphql.NewNonNull(ofType)
+		}
+	case *ast.List:
+		if ofType := astInputType(schema, t.Type); ofType != nil {
+			return graphql.NewList(ofType)
+		}
+	case *ast.Named:
+		if t.Name != nil {
+			return schema.Type(t.Name.Value)
+		}
+	}
+	return nil
+}
+
+// Checks if an empty string can't be a value of the named input type `t`, i.e.
+// the `Int`, `Float` and `Boolean` scalars and enums. Other scalars, like
+// `String`, `ID` or custom ones, may legitimately be empty.
+func rejectsEmptyString(t graphql.Type) bool {
+	switch t {
+	case graphql.Int, graphql.Float, graphql.Boolean:
+		return true
+	}
+	_, ok := t.(*graphql.Enum)
+	return ok
+}
+
+// Returns `value` of the input type `t` with the empty strings of the types
+// rejecting them replaced with null, walking lists and input objects.
+func coerceEmptyString(value interface{}, t graphql.Type) interface{} {
+	switch t := t.(type) {
+	case *graphql.NonNull:
+		return coerceEmptyString(value, t.OfType)
+	case *graphql.List:
+		items, ok := value.([]interface{})
+		if !ok {
+			// a single value is coerced to a list of one item
+			return coerceEmptyString(value, t.OfType)
+		}
+		for i := range items {
+			items[i] = coerceEmptyString(items[i], t.OfType)
+		}
+		return items
+	case *graphql.InputObject:
+		if fields, ok := value.(map[string]interface{}); ok {
+			for name, field := range t.Fields() {
+				if fieldValue, ok := fields[name]; ok {
+					fields[name] = coerceEmptyString(fieldValue, field.Type)
+				}
+			}
+		}
+		return value
+	}
+	if s, ok := value.(string); ok && s == "" && rejectsEmptyString(t) {
+		return nil
+	}
+	return value
+}
+
+// Replaces empty string values of the request variables with null where the
+// declared type can't have an empty string value, also in lists and input
+// objects. Empty strings of `String`, `ID` and custom scalars are legitimate
+// values and kept.
+func coerceEmptyStrings(schema *graphql.Schema, query *queryDocument) {
+	variables := query.params.VariableValues
+	if len(variables) == 0 {
+		return
+	}
+	_, operation, err := query.Operation()
+	if err != nil {
+		return
+	}
+	for _, definition := range operation.VariableDefinitions {
+		if definition.Variable == nil || definition.Variable.Name == nil {
+			continue
+		}
+		name := definition.Variable.Name.Value
+		value, ok := variables[name]
+		if !ok {
+			continue
+		}
+		if t := astInputType(schema, definition.Type); t != nil {
+			variables[name] = coerceEmptyString(value, t)
+		}
+	}
+}
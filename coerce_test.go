@@ -0,0 +1,159 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+var echoSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"echo": &graphql.Field{
+				Type: graphql.String,
+				Args: graphql.FieldConfigArgument{
+					"count": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+					"text": &graphql.ArgumentConfig{
+						Type: graphql.String,
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if _, ok := p.Args["count"]; ok {
+						return "count", nil
+					}
+					if text, ok := p.Args["text"].(string); ok {
+						return "text:" + text, nil
+					}
+					return "none", nil
+				},
+			},
+		},
+	}),
+})
+
+func postEcho(t *testing.T, app *GraphQLApp, variables map[string]interface{}) string {
+	router := setupRouter(app)
+	type echoResponse struct {
+		Data struct {
+			Echo string `json:"echo"`
+		} `json:"data"`
+	}
+
+	query := map[string]interface{}{
+		"query":         "query echo ($count: Int, $text: String) { echo(count: $count, text: $text) }",
+		"operationName": "echo",
+		"variables":     variables,
+	}
+	queryBody, _ := json.Marshal(query)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(queryBody))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Request failed. Code: %d", recorder.Code)
+	}
+	var res echoResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	return res.Data.Echo
+}
+
+func TestEmptyStringAsNullPOST(t *testing.T) {
+	app := New(echoSchema)
+	app.EmptyStringAsNull = true
+
+	if echo := postEcho(t, app, map[string]interface{}{"count": ""}); echo != "none" {
+		t.Errorf("Empty Int variable not coerced. Found %s, expected %s", echo, "none")
+	}
+	if echo := postEcho(t, app, map[string]interface{}{"text": ""}); echo != "text:" {
+		t.Errorf("Empty String variable coerced. Found %s, expected %s", echo, "text:")
+	}
+}
+
+func TestEmptyStringAsNullDisabledPOST(t *testing.T) {
+	app := New(echoSchema)
+
+	if echo := postEcho(t, app, map[string]interface{}{"count": ""}); echo == "none" {
+		t.Errorf("Empty Int variable coerced without the option")
+	}
+}
+
+var filterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "Filter",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"limit": &graphql.InputObjectFieldConfig{Type: graphql.Int},
+		"name":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+		"ids":   &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.Int)},
+	},
+})
+
+var lookupSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"lookup": &graphql.Field{
+				Type: graphql.String,
+				Args: graphql.FieldConfigArgument{
+					"id":     &graphql.ArgumentConfig{Type: graphql.ID},
+					"filter": &graphql.ArgumentConfig{Type: filterInput},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					args, err := json.Marshal(p.Args)
+					return string(args), err
+				},
+			},
+		},
+	}),
+})
+
+func TestEmptyStringAsNullNestedPOST(t *testing.T) {
+	app := New(lookupSchema)
+	app.EmptyStringAsNull = true
+	router := setupRouter(app)
+
+	query, _ := json.Marshal(map[string]interface{}{
+		"query": "query lookup ($id: ID, $filter: Filter) { lookup(id: $id, filter: $filter) }",
+		"variables": map[string]interface{}{
+			"id": "",
+			"filter": map[string]interface{}{
+				"limit": "",
+				"name":  "",
+				"ids":   []interface{}{1, ""},
+			},
+		},
+	})
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(query))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	var res struct {
+		Data struct {
+			Lookup string `json:"lookup"`
+		} `json:"data"`
+		Errors []interface{} `json:"errors"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Response unmarshal failed. Err: %v", err)
+	}
+	if len(res.Errors) > 0 {
+		t.Fatalf("Request failed. Body: %s", recorder.Body.String())
+	}
+	expected := `{"filter":{"ids":[1,null],"name":""},"id":""}`
+	if res.Data.Lookup != expected {
+		t.Errorf("Coerced arguments incorrect. Found %s, expected %s", res.Data.Lookup, expected)
+	}
+}
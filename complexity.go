@@ -0,0 +1,118 @@
+package graphqlgin
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+// Response header carrying the computed cost of the executed operation
+const QueryCostHeader = "X-Query-Cost"
+
+// Function to compute the cost of a selected field.
+//
+// `args` are the field arguments with variables substituted, and `childCost`
+// is the total cost of the field's own selections.
+type CostFn func(field *graphql.FieldDefinition, args map[string]interface{}, childCost int) int
+
+// Upper bound of computed costs and result size estimates, which saturate at
+// it instead of overflowing for huge page sizes.
+const maxCost = math.MaxInt32
+
+// Returns `a + b` of non negative values, saturating at `maxCost`.
+func saturatingAdd(a, b int) int {
+	if a > maxCost-b {
+		return maxCost
+	}
+	return a + b
+}
+
+// Returns `a * b` of non negative values, saturating at `maxCost`.
+func saturatingMul(a, b int) int {
+	if a != 0 && b > maxCost/a {
+		return maxCost
+	}
+	return a * b
+}
+
+// Returns the page size requested through the common `first` or `limit`
+// arguments, or 1 if neither is provided. Sizes are capped at `maxCost`.
+func listMultiplier(args map[string]interface{}) int {
+	for _, name := range []string{"first", "limit"} {
+		if size, ok := args[name].(int); ok && size > 0 {
+			if size > maxCost {
+				return maxCost
+			}
+			return size
+		}
+		if size, ok := args[name].(float64); ok && size > 0 {
+			if size > maxCost {
+				return maxCost
+			}
+			return int(size)
+		}
+	}
+	return 1
+}
+
+// Checks if `t` is a list type, unwrapping any non-null wrapper.
+func isListType(t graphql.Type) bool {
+	if nonNull, ok := t.(*graphql.NonNull); ok {
+		t = nonNull.OfType
+	}
+	_, ok := t.(*graphql.List)
+	return ok
+}
+
+// Default `CostFn`: each field costs 1, and the cost of selections on list
+// fields is multiplied by the requested page size (`first` or `limit` argument).
+func DefaultCostFn(field *graphql.FieldDefinition, args map[string]interface{}, childCost int) int {
+	if isListType(field.Type) {
+		return saturatingAdd(1, saturatingMul(childCost, listMultiplier(args)))
+	}
+	return saturatingAdd(1, childCost)
+}
+
+// Sums the cost of `fields` and their selections using `costFn`, saturating at
+// `maxCost`.
+func fieldsCost(fields []*selectedField, costFn CostFn) int {
+	total := 0
+	for _, field := range fields {
+		if field.Definition == nil {
+			// introspection and unknown fields cost 1 like any other field
+			total = saturatingAdd(total, saturatingAdd(1, fieldsCost(field.Children, costFn)))
+			continue
+		}
+		cost := costFn(field.Definition, field.Args, fieldsCost(field.Children, costFn))
+		if cost < 0 {
+			cost = 0
+		}
+		total = saturatingAdd(total, cost)
+	}
+	return total
+}
+
+// Computes the cost of the requested operation. Returns false if the request
+// could not be analyzed, leaving the error reporting to execution.
+func (app *GraphQLApp) operationCost(query *queryDocument) (int, bool) {
+	fields, err := query.Fields(&app.Schema)
+	if err != nil {
+		return 0, false
+	}
+	costFn := app.CostFn
+	if costFn == nil {
+		costFn = DefaultCostFn
+	}
+	return fieldsCost(fields, costFn), true
+}
+
+// Adds `cost` to the `X-Query-Cost` response header, so batched requests report
+// the total cost of their operations.
+func addQueryCost(c *gin.Context, cost int) {
+	if previous, err := strconv.Atoi(c.Writer.Header().Get(QueryCostHeader)); err == nil {
+		cost += previous
+	}
+	c.Header(QueryCostHeader, strconv.Itoa(cost))
+}
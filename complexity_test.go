@@ -0,0 +1,199 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+var itemObject = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Item",
+	Fields: graphql.Fields{
+		"id": &graphql.Field{
+			Type: graphql.Int,
+		},
+		"name": &graphql.Field{
+			Type: graphql.String,
+		},
+	},
+})
+
+var complexitySchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+			"items": &graphql.Field{
+				Type: graphql.NewList(itemObject),
+				Args: graphql.FieldConfigArgument{
+					"first": &graphql.ArgumentConfig{
+						Type: graphql.Int,
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					first, _ := p.Args["first"].(int)
+					items := []map[string]interface{}{}
+					for i := 0; i < first; i++ {
+						items = append(items, map[string]interface{}{"id": i, "name": "item"})
+					}
+					return items, nil
+				},
+			},
+		},
+	}),
+})
+
+// Creates a schema of items selecting their own items, to nest list fields.
+func newNestedSchema() graphql.Schema {
+	var item *graphql.Object
+	item = graphql.NewObject(graphql.ObjectConfig{
+		Name: "NestedItem",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id": &graphql.Field{
+					Type: graphql.Int,
+				},
+				"items": &graphql.Field{
+					Type: graphql.NewList(item),
+					Args: graphql.FieldConfigArgument{
+						"first": &graphql.ArgumentConfig{
+							Type: graphql.Int,
+						},
+					},
+				},
+			}
+		}),
+	})
+	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"items": &graphql.Field{
+					Type: graphql.NewList(item),
+					Args: graphql.FieldConfigArgument{
+						"first": &graphql.ArgumentConfig{
+							Type: graphql.Int,
+						},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return []interface{}{}, nil
+					},
+				},
+			},
+		}),
+	})
+	return schema
+}
+
+// query nesting page sizes whose product overflows int
+const hugeNestedQuery = `{"query": "{ items(first: 2147483647) { items(first: 2147483647) { items(first: 2147483647) { id } } } }"}`
+
+func TestQueryCostHeaderPOST(t *testing.T) {
+	app := New(complexitySchema)
+	app.CostFn = DefaultCostFn
+	router := setupRouter(app)
+
+	query := map[string]interface{}{
+		"query":         "query items ($first: Int) { hello items(first: $first) { id name } }",
+		"operationName": "items",
+		"variables": map[string]interface{}{
+			"first": 10,
+		},
+	}
+	queryBody, _ := json.Marshal(query)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(queryBody))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Request failed. Code: %d", recorder.Code)
+	}
+	// hello: 1, items: 1 + 10 * (id: 1 + name: 1)
+	if cost := recorder.Header().Get(QueryCostHeader); cost != "22" {
+		t.Errorf("Query cost incorrect. Found %s, expected %s", cost, "22")
+	}
+}
+
+func TestQueryCostHeaderDisabledPOST(t *testing.T) {
+	app := New(complexitySchema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if cost := recorder.Header().Get(QueryCostHeader); cost != "" {
+		t.Errorf("Query cost header found without complexity analysis. Found %s", cost)
+	}
+}
+
+func TestMaxComplexityPOST(t *testing.T) {
+	app := New(complexitySchema)
+	app.MaxComplexity = 20
+	router := setupRouter(app)
+
+	for first, expected := range map[int]string{
+		5:  `{"data":{"items":[{"id":0},{"id":1},{"id":2},{"id":3},{"id":4}]}}`,
+		50: `{"errors":[{"message":"query too complex (cost 51 exceeds the maximum complexity of 20)"}]}`,
+	} {
+		query := map[string]interface{}{
+			"query":     "query ($first: Int) { items(first: $first) { id } }",
+			"variables": map[string]interface{}{"first": first},
+		}
+		queryBody, _ := json.Marshal(query)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(queryBody))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if body := recorder.Body.String(); body != expected {
+			t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+		}
+	}
+}
+
+func TestMaxComplexityIntrospectionPOST(t *testing.T) {
+	app := New(complexitySchema)
+	app.MaxComplexity = 4
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello __schema { types { fields { name } } } }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	// hello: 1, __schema: 1 + types: 1 + fields: 1 + name: 1
+	expected := `{"errors":[{"message":"query too complex (cost 5 exceeds the maximum complexity of 4)"}]}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestMaxComplexityHugePageSizesPOST(t *testing.T) {
+	app := New(newNestedSchema())
+	app.MaxComplexity = 100
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(hugeNestedQuery))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"errors":[{"message":"query too complex (cost 2147483647 exceeds the maximum complexity of 100)"}]}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
@@ -0,0 +1,107 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Returns the preferred encoding among gzip and deflate accepted by the
+// `Accept-Encoding` header, or an empty string if neither is accepted.
+func acceptedEncoding(acceptEncoding string) string {
+	qualities := map[string]float64{}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		qualities[strings.ToLower(strings.TrimSpace(fields[0]))] = quality
+	}
+	for _, encoding := range []string{"gzip", "deflate"} {
+		quality, ok := qualities[encoding]
+		if !ok {
+			quality, ok = qualities["*"]
+		}
+		if ok && quality > 0 {
+			return encoding
+		}
+	}
+	return ""
+}
+
+// Response writer holding back the response, so it can be compressed once its
+// size is known.
+type compressResponseWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *compressResponseWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *compressResponseWriter) WriteHeaderNow() {}
+
+func (w *compressResponseWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *compressResponseWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *compressResponseWriter) Status() int {
+	return w.status
+}
+
+func (w *compressResponseWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *compressResponseWriter) Written() bool {
+	return w.body.Len() > 0
+}
+
+// Holds back the response of `c` and returns a function writing it, compressed
+// when the client accepts it and it is at least `CompressionMinSize` bytes.
+func (app *GraphQLApp) compressResponse(c *gin.Context) func() {
+	w := &compressResponseWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+	c.Writer = w
+	return func() {
+		c.Writer = w.ResponseWriter
+		header := c.Writer.Header()
+		header.Add("Vary", "Accept-Encoding")
+
+		encoding := acceptedEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || w.body.Len() == 0 || w.body.Len() < app.CompressionMinSize {
+			c.Writer.WriteHeader(w.status)
+			c.Writer.Write(w.body.Bytes())
+			return
+		}
+
+		header.Set("Content-Encoding", encoding)
+		header.Del("Content-Length")
+		c.Writer.WriteHeader(w.status)
+		var compressor io.WriteCloser
+		if encoding == "gzip" {
+			compressor = gzip.NewWriter(c.Writer)
+		} else {
+			compressor, _ = flate.NewWriter(c.Writer, flate.DefaultCompression)
+		}
+		compressor.Write(w.body.Bytes())
+		compressor.Close()
+	}
+}
@@ -0,0 +1,89 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func compressedRequest(app *GraphQLApp, fields int, acceptEncoding string) *httptest.ResponseRecorder {
+	aliases := []string{}
+	for i := 0; i < fields; i++ {
+		aliases = append(aliases, fmt.Sprintf("a%d: hello", i))
+	}
+	router := setupRouter(app)
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(fmt.Sprintf(`{"query": "{ %s }"}`, strings.Join(aliases, " "))))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept-Encoding", acceptEncoding)
+
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestCompressionPOST(t *testing.T) {
+	app := New(schema)
+	app.Compress = true
+	app.CompressionMinSize = 100
+
+	for _, encoding := range []string{"gzip", "deflate"} {
+		recorder := compressedRequest(app, 50, encoding+", br")
+
+		if contentEncoding := recorder.Header().Get("Content-Encoding"); contentEncoding != encoding {
+			t.Fatalf("Content encoding incorrect. Found %s, expected %s", contentEncoding, encoding)
+		}
+		if vary := recorder.Header().Get("Vary"); vary != "Accept-Encoding" {
+			t.Errorf("Vary header incorrect. Found %s, expected Accept-Encoding", vary)
+		}
+		var reader io.Reader
+		if encoding == "gzip" {
+			reader, _ = gzip.NewReader(recorder.Body)
+		} else {
+			reader = flate.NewReader(recorder.Body)
+		}
+		body, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Decompressing failed. Err: %v", err)
+		}
+		if !strings.HasPrefix(string(body), `{"data":{`) || !strings.Contains(string(body), `"a49":"world"`) {
+			t.Errorf("Response incorrect. Found %s", body)
+		}
+	}
+}
+
+func TestCompressionSkippedPOST(t *testing.T) {
+	app := New(schema)
+	app.Compress = true
+	app.CompressionMinSize = 100
+
+	cases := []struct {
+		name           string
+		app            *GraphQLApp
+		fields         int
+		acceptEncoding string
+	}{
+		{"below threshold", app, 1, "gzip"},
+		{"not accepted", app, 50, "br, gzip;q=0"},
+		{"not enabled", New(schema), 50, "gzip"},
+	}
+	for _, tc := range cases {
+		recorder := compressedRequest(tc.app, tc.fields, tc.acceptEncoding)
+
+		if contentEncoding := recorder.Header().Get("Content-Encoding"); contentEncoding != "" {
+			t.Errorf("Response %s compressed. Found %s", tc.name, contentEncoding)
+		}
+		if body := recorder.Body.String(); !strings.HasPrefix(body, `{"data":{`) {
+			t.Errorf("Response %s incorrect. Found %s", tc.name, body)
+		}
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Status code %s incorrect. Found %d, expected %d", tc.name, recorder.Code, http.StatusOK)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package graphqlgin
+
+import (
+	"time"
+)
+
+// Snapshot of the effective configuration of a `GraphQLApp`, i.e. to log at
+// startup. Hook and backend fields report whether they are configured.
+type Config struct {
+	ContextProviders           int           `json:"contextProviders"`
+	Debug                      bool          `json:"debug"`
+	MaxJSONBodySize            int64         `json:"maxJSONBodySize"`
+	MaxMultipartBodySize       int64         `json:"maxMultipartBodySize"`
+	ComplexityAnalysis         bool          `json:"complexityAnalysis"`
+	IgnoreTrailingSlash        bool          `json:"ignoreTrailingSlash"`
+	EmptyStringAsNull          bool          `json:"emptyStringAsNull"`
+	ParamsMutator              bool          `json:"paramsMutator"`
+	UploadStore                bool          `json:"uploadStore"`
+	UploadErrorStatus          bool          `json:"uploadErrorStatus"`
+	ContextProviderTimeout     time.Duration `json:"contextProviderTimeout"`
+	ResultOperationName        bool          `json:"resultOperationName"`
+	BlockDeprecatedFields      bool          `json:"blockDeprecatedFields"`
+	MaxUploadSize              int64         `json:"maxUploadSize"`
+	MaxTotalUploadSize         int64         `json:"maxTotalUploadSize"`
+	MaxEstimatedResultSize     int           `json:"maxEstimatedResultSize"`
+	CreatedOnLocation          bool          `json:"createdOnLocation"`
+	UploadsWithoutMap          bool          `json:"uploadsWithoutMap"`
+	ErrorFormatter             bool          `json:"errorFormatter"`
+	MaskErrors                 bool          `json:"maskErrors"`
+	SuppressSuggestions        bool          `json:"suppressSuggestions"`
+	FeatureFields              int           `json:"featureFields"`
+	StatusCodeFn               bool          `json:"statusCodeFn"`
+	DeduplicateUploads         bool          `json:"deduplicateUploads"`
+	PanicHandler               bool          `json:"panicHandler"`
+	RequestTimeout             time.Duration `json:"requestTimeout"`
+	MaxSubscriptionConnections int           `json:"maxSubscriptionConnections"`
+	SubscriptionStartupJitter  time.Duration `json:"subscriptionStartupJitter"`
+	ConcurrentContextProviders bool          `json:"concurrentContextProviders"`
+	SchemaVersion              string        `json:"schemaVersion"`
+	PersistedQueryCache        bool          `json:"persistedQueryCache"`
+	UploadStoreRetries         int           `json:"uploadStoreRetries"`
+	UploadStoreRetryBackoff    time.Duration `json:"uploadStoreRetryBackoff"`
+	ContextProviderErrorStatus int           `json:"contextProviderErrorStatus"`
+	Compress                   bool          `json:"compress"`
+	CompressionMinSize         int           `json:"compressionMinSize"`
+	OrderedFields              bool          `json:"orderedFields"`
+	MetricsRecorder            bool          `json:"metricsRecorder"`
+	Tracer                     bool          `json:"tracer"`
+	MaxConcurrentUploads       int           `json:"maxConcurrentUploads"`
+	UploadLimitKey             bool          `json:"uploadLimitKey"`
+	Logger                     bool          `json:"logger"`
+	SummaryLogger              bool          `json:"summaryLogger"`
+	MaxQueryDepth              int           `json:"maxQueryDepth"`
+	GrowUploadLists            bool          `json:"growUploadLists"`
+	MaxComplexity              int           `json:"maxComplexity"`
+	DisableIntrospection       bool          `json:"disableIntrospection"`
+	QueryCacheSize             int           `json:"queryCacheSize"`
+	RootObjectProvider         bool          `json:"rootObjectProvider"`
+	UploadToDisk               bool          `json:"uploadToDisk"`
+	UploadTempDir              string        `json:"uploadTempDir"`
+	AllowedUploadMIMETypes     []string      `json:"allowedUploadMIMETypes"`
+	SniffUploadMIMETypes       bool          `json:"sniffUploadMIMETypes"`
+	MaxBodyBytes               int64         `json:"maxBodyBytes"`
+	CSRFPrevention             bool          `json:"csrfPrevention"`
+	CSRFPreventionHeader       string        `json:"csrfPreventionHeader"`
+	RateLimiter                bool          `json:"rateLimiter"`
+	OnBeforeExecute            bool          `json:"onBeforeExecute"`
+	OnAfterExecute             bool          `json:"onAfterExecute"`
+	ResponseExtensions         bool          `json:"responseExtensions"`
+	ResponseEncoder            bool          `json:"responseEncoder"`
+	PrettyResponse             bool          `json:"prettyResponse"`
+	ResponseWriter             bool          `json:"responseWriter"`
+	TracingEnabled             bool          `json:"tracingEnabled"`
+	HealthChecks               int           `json:"healthChecks"`
+	CacheControl               bool          `json:"cacheControl"`
+	DefaultCacheMaxAge         time.Duration `json:"defaultCacheMaxAge"`
+	PublicCache                bool          `json:"publicCache"`
+	TrustedQueries             int           `json:"trustedQueries"`
+	TrustedQueriesOnly         bool          `json:"trustedQueriesOnly"`
+}
+
+// Returns a snapshot of the current configuration of the app.
+func (app *GraphQLApp) Config() Config {
+	return Config{
+		ContextProviders:           len(app.ContextProviders) + len(app.namedProviders),
+		Debug:                      app.Debug,
+		MaxJSONBodySize:            app.MaxJSONBodySize,
+		MaxMultipartBodySize:       app.MaxMultipartBodySize,
+		ComplexityAnalysis:         app.CostFn != nil || app.MaxComplexity > 0,
+		IgnoreTrailingSlash:        app.IgnoreTrailingSlash,
+		EmptyStringAsNull:          app.EmptyStringAsNull,
+		ParamsMutator:              app.ParamsMutator != nil,
+		UploadStore:                app.UploadStore != nil,
+		UploadErrorStatus:          app.UploadErrorStatus,
+		ContextProviderTimeout:     app.ContextProviderTimeout,
+		ResultOperationName:        app.ResultOperationName,
+		BlockDeprecatedFields:      app.BlockDeprecatedFields,
+		MaxUploadSize:              app.MaxUploadSize,
+		MaxTotalUploadSize:         app.MaxTotalUploadSize,
+		MaxEstimatedResultSize:     app.MaxEstimatedResultSize,
+		CreatedOnLocation:          app.CreatedOnLocation,
+		UploadsWithoutMap:          app.UploadsWithoutMap,
+		ErrorFormatter:             app.ErrorFormatter != nil,
+		MaskErrors:                 app.MaskErrors,
+		SuppressSuggestions:        app.SuppressSuggestions,
+		FeatureFields:              len(app.FeatureFields),
+		StatusCodeFn:               app.StatusCodeFn != nil,
+		DeduplicateUploads:         app.DeduplicateUploads,
+		PanicHandler:               app.PanicHandler != nil,
+		RequestTimeout:             app.RequestTimeout,
+		MaxSubscriptionConnections: app.MaxSubscriptionConnections,
+		SubscriptionStartupJitter:  app.SubscriptionStartupJitter,
+		ConcurrentContextProviders: app.ConcurrentContextProviders,
+		SchemaVersion:              app.SchemaVersion,
+		PersistedQueryCache:        app.PersistedQueryCache != nil,
+		UploadStoreRetries:         app.UploadStoreRetries,
+		UploadStoreRetryBackoff:    app.UploadStoreRetryBackoff,
+		ContextProviderErrorStatus: app.ContextProviderErrorStatus,
+		Compress:                   app.Compress,
+		CompressionMinSize:         app.CompressionMinSize,
+		OrderedFields:              app.OrderedFields,
+		MetricsRecorder:            app.MetricsRecorder != nil,
+		Tracer:                     app.Tracer != nil,
+		MaxConcurrentUploads:       app.MaxConcurrentUploads,
+		UploadLimitKey:             app.UploadLimitKey != nil,
+		Logger:                     app.Logger != nil,
+		SummaryLogger:              app.SummaryLogger != nil,
+		MaxQueryDepth:              app.MaxQueryDepth,
+		GrowUploadLists:            app.GrowUploadLists,
+		MaxComplexity:              app.MaxComplexity,
+		DisableIntrospection:       app.DisableIntrospection,
+		QueryCacheSize:             app.QueryCacheSize,
+		RootObjectProvider:         app.RootObjectProvider != nil,
+		UploadToDisk:               app.UploadToDisk,
+		UploadTempDir:              app.UploadTempDir,
+		AllowedUploadMIMETypes:     append([]string(nil), app.AllowedUploadMIMETypes...),
+		SniffUploadMIMETypes:       app.SniffUploadMIMETypes,
+		MaxBodyBytes:               app.MaxBodyBytes,
+		CSRFPrevention:             app.CSRFPrevention,
+		CSRFPreventionHeader:       app.CSRFPreventionHeader,
+		RateLimiter:                app.RateLimiter != nil,
+		OnBeforeExecute:            app.OnBeforeExecute != nil,
+		OnAfterExecute:             app.OnAfterExecute != nil,
+		ResponseExtensions:         app.ResponseExtensions != nil,
+		ResponseEncoder:            app.ResponseEncoder != nil,
+		PrettyResponse:             app.PrettyResponse,
+		ResponseWriter:             app.ResponseWriter != nil,
+		TracingEnabled:             app.TracingEnabled,
+		HealthChecks:               len(app.HealthChecks),
+		CacheControl:               app.CacheControl,
+		DefaultCacheMaxAge:         app.DefaultCacheMaxAge,
+		PublicCache:                app.PublicCache,
+		TrustedQueries:             len(app.TrustedQueries),
+		TrustedQueriesOnly:         app.TrustedQueriesOnly,
+	}
+}
@@ -0,0 +1,47 @@
+package graphqlgin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfig(t *testing.T) {
+	app := New(schema, TraceParentProvider)
+	app.Debug = true
+	app.MaxUploadSize = 1024
+	app.ContextProviderTimeout = time.Second
+	app.CostFn = DefaultCostFn
+	app.PublicCache = true
+
+	config := app.Config()
+	if config.ContextProviders != 2 {
+		t.Errorf("Context providers incorrect. Found %d, expected %d", config.ContextProviders, 2)
+	}
+	if !config.Debug {
+		t.Errorf("Debug incorrect. Found %v, expected %v", config.Debug, true)
+	}
+	if config.MaxUploadSize != 1024 {
+		t.Errorf("Max upload size incorrect. Found %d, expected %d", config.MaxUploadSize, 1024)
+	}
+	if config.ContextProviderTimeout != time.Second {
+		t.Errorf("Context provider timeout incorrect. Found %s, expected %s", config.ContextProviderTimeout, time.Second)
+	}
+	if !config.ComplexityAnalysis {
+		t.Errorf("Complexity analysis incorrect. Found %v, expected %v", config.ComplexityAnalysis, true)
+	}
+	if !config.PublicCache {
+		t.Errorf("Public cache incorrect. Found %v, expected %v", config.PublicCache, true)
+	}
+	if config.UploadStore || config.BlockDeprecatedFields {
+		t.Errorf("Unset options reported as enabled. Found %+v", config)
+	}
+}
+
+func TestConfigMaxComplexity(t *testing.T) {
+	app := New(schema)
+	app.MaxComplexity = 10
+
+	if config := app.Config(); !config.ComplexityAnalysis {
+		t.Errorf("Complexity analysis incorrect. Found %v, expected %v", config.ComplexityAnalysis, true)
+	}
+}
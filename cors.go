@@ -0,0 +1,98 @@
+package graphqlgin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Request headers allowed by `CORSMiddleware` when `CORSConfig.AllowHeaders` is
+// empty, covering the headers commonly sent by Apollo and urql clients
+var DefaultCORSAllowHeaders = []string{
+	"Accept",
+	"Authorization",
+	"Content-Type",
+	"apollo-require-preflight",
+	"apollographql-client-name",
+	"apollographql-client-version",
+	"x-apollo-operation-name",
+}
+
+// Configuration of `CORSMiddleware`
+type CORSConfig struct {
+	// Origins allowed to send requests, `*` allows any origin
+	AllowOrigins []string
+	// Request headers allowed in requests. When empty, `DefaultCORSAllowHeaders`
+	// are allowed.
+	AllowHeaders []string
+	// Allows requests with cookies and authorization headers
+	AllowCredentials bool
+	// Duration preflight responses may be cached for. Zero leaves it to the browser.
+	MaxAge time.Duration
+}
+
+// Returns the value of the `Access-Control-Allow-Origin` header for `origin`,
+// or an empty string if the origin is not allowed.
+func (config CORSConfig) allowedOrigin(origin string) string {
+	for _, allowed := range config.AllowOrigins {
+		if allowed == "*" {
+			// browsers reject the wildcard for credentialed requests
+			if config.AllowCredentials {
+				return origin
+			}
+			return "*"
+		}
+		if strings.EqualFold(allowed, origin) {
+			return origin
+		}
+	}
+	return ""
+}
+
+// Returns a `gin.HandlerFunc` adding the CORS headers of `config` to the
+// responses of cross-origin requests, and replying to preflight requests. Use
+// it on the router, i.e. `router.Use(app.CORSMiddleware(config))`, so it also
+// sees the OPTIONS requests, which have no route.
+func (app *GraphQLApp) CORSMiddleware(config CORSConfig) gin.HandlerFunc {
+	allowHeaders := config.AllowHeaders
+	if len(allowHeaders) == 0 {
+		allowHeaders = DefaultCORSAllowHeaders
+	}
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		c.Header("Vary", "Origin")
+		allowedOrigin := config.allowedOrigin(origin)
+		if allowedOrigin == "" {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+		c.Header("Access-Control-Allow-Origin", allowedOrigin)
+		if config.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		if !preflight {
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", strings.Join(allowHeaders, ", "))
+		if config.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", strconv.Itoa(int(config.MaxAge/time.Second)))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,97 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newCORSRouter(app *GraphQLApp, config CORSConfig) *gin.Engine {
+	router := gin.New()
+	router.Use(app.CORSMiddleware(config))
+	router.POST("/", app.Handler())
+	return router
+}
+
+func TestCORSPreflight(t *testing.T) {
+	app := New(schema)
+	router := newCORSRouter(app, CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	})
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("OPTIONS", "/", nil)
+	request.Header.Add("Origin", "https://app.example.com")
+	request.Header.Add("Access-Control-Request-Method", "POST")
+	request.Header.Add("Access-Control-Request-Headers", "content-type, apollographql-client-name")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNoContent {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusNoContent)
+	}
+	for header, expected := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "3600",
+	} {
+		if found := recorder.Header().Get(header); found != expected {
+			t.Errorf("Header %s incorrect. Found %s, expected %s", header, found, expected)
+		}
+	}
+	if headers := recorder.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(headers, "apollographql-client-name") {
+		t.Errorf("Default allowed headers incorrect. Found %s", headers)
+	}
+}
+
+func TestCORSRequestPOST(t *testing.T) {
+	app := New(schema)
+	router := newCORSRouter(app, CORSConfig{AllowOrigins: []string{"*"}})
+
+	for origin, expected := range map[string]string{
+		"https://app.example.com": "*",
+		"":                        "",
+	} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+		request.Header.Add("Content-Type", "application/json")
+		if origin != "" {
+			request.Header.Add("Origin", origin)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		if found := recorder.Header().Get("Access-Control-Allow-Origin"); found != expected {
+			t.Errorf("Allowed origin incorrect. Found %s, expected %s", found, expected)
+		}
+		if body := recorder.Body.String(); body != `{"data":{"hello":"world"}}` {
+			t.Errorf("Response incorrect. Found %s", body)
+		}
+	}
+}
+
+func TestCORSDisallowedOrigin(t *testing.T) {
+	app := New(schema)
+	router := newCORSRouter(app, CORSConfig{AllowOrigins: []string{"https://app.example.com"}})
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("OPTIONS", "/", nil)
+	request.Header.Add("Origin", "https://evil.example.com")
+	request.Header.Add("Access-Control-Request-Method", "POST")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusForbidden)
+	}
+	if found := recorder.Header().Get("Access-Control-Allow-Origin"); found != "" {
+		t.Errorf("Allowed origin incorrect. Found %s, expected none", found)
+	}
+}
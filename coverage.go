@@ -0,0 +1,82 @@
+package graphqlgin
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+// Schema coverage of a query, the types and fields it selects
+type CoverageReport struct {
+	// Names of the types selected, including the types of leaf fields
+	Types []string `json:"types"`
+	// Selected fields as `Type.field`
+	Fields []string `json:"fields"`
+}
+
+// Collects the types and fields of `fields` and their selections into `types`
+// and `fieldNames`.
+func collectCoverage(fields []*selectedField, types map[string]bool, fieldNames map[string]bool) {
+	for _, field := range fields {
+		if field.Definition == nil || field.ParentType == nil {
+			// unknown and introspection fields are not part of the schema
+			continue
+		}
+		types[field.ParentType.Name()] = true
+		if fieldType, ok := graphql.GetNamed(field.Definition.Type).(graphql.Type); ok {
+			types[fieldType.Name()] = true
+		}
+		fieldNames[field.ParentType.Name()+"."+field.Definition.Name] = true
+		collectCoverage(field.Children, types, fieldNames)
+	}
+}
+
+// Returns the sorted keys of `set`.
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Returns the schema coverage of the operation requested by `query`.
+func (app *GraphQLApp) queryCoverage(query *queryDocument) (*CoverageReport, error) {
+	fields, err := query.Fields(&app.Schema)
+	if err != nil {
+		return nil, err
+	}
+	types, fieldNames := map[string]bool{}, map[string]bool{}
+	collectCoverage(fields, types, fieldNames)
+	return &CoverageReport{
+		Types:  sortedKeys(types),
+		Fields: sortedKeys(fieldNames),
+	}, nil
+}
+
+// Returns a `gin.HandlerFunc` replying with the `CoverageReport` of the query in
+// the request, without executing it, i.e. to analyze the query shapes of clients.
+func (app *GraphQLApp) CoverageHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var params GraphQLRequestParams
+		if err := c.ShouldBind(&params); err != nil {
+			c.JSON(
+				http.StatusBadRequest,
+				graphqlErrorReply("invalid request body", err),
+			)
+			return
+		}
+		report, err := app.queryCoverage(&queryDocument{params: &params})
+		if err != nil {
+			c.JSON(
+				http.StatusBadRequest,
+				graphqlErrorReply("invalid query", err),
+			)
+			return
+		}
+		c.JSON(http.StatusOK, report)
+	}
+}
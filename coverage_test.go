@@ -0,0 +1,43 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCoverageHandler(t *testing.T) {
+	app := New(complexitySchema)
+	router := gin.Default()
+	router.POST("/coverage", app.CoverageHandler())
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/coverage", bytes.NewBufferString(`{"query": "{ hello items(first: 1000000) { ...item __typename } } fragment item on Item { id }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"types":["Int","Item","Query","String"],"fields":["Item.id","Query.hello","Query.items"]}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestCoverageHandlerInvalidQuery(t *testing.T) {
+	app := New(complexitySchema)
+	router := gin.Default()
+	router.POST("/coverage", app.CoverageHandler())
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/coverage", bytes.NewBufferString(`{"query": "{ hello"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,40 @@
+package graphqlgin
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Header required by `CSRFPrevention` when `CSRFPreventionHeader` is empty
+const DefaultCSRFPreventionHeader = "Apollo-Require-Preflight"
+
+// Returns the header required by `CSRFPrevention`.
+func (app *GraphQLApp) csrfPreventionHeader() string {
+	if app.CSRFPreventionHeader != "" {
+		return app.CSRFPreventionHeader
+	}
+	return DefaultCSRFPreventionHeader
+}
+
+// Returns an error if the request could have been sent cross-site without a
+// preflight, i.e. a POST with a content type allowed for HTML forms, and lacks
+// the CSRF prevention header. Browsers only send custom headers after a
+// successful preflight.
+func (app *GraphQLApp) checkCSRF(c *gin.Context) error {
+	if c.Request.Method != "POST" {
+		// GET requests only run queries
+		return nil
+	}
+	switch c.ContentType() {
+	case gin.MIMEMultipartPOSTForm, gin.MIMEPOSTForm, gin.MIMEPlain:
+	default:
+		// other content types require a preflight
+		return nil
+	}
+	header := app.csrfPreventionHeader()
+	if c.GetHeader(header) == "" {
+		return fmt.Errorf("%s requests must set the %s header", c.ContentType(), header)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCSRFPreventionPOST(t *testing.T) {
+	app := New(schema)
+	app.CSRFPrevention = true
+	app.CSRFPreventionHeader = "X-GraphQL-CSRF"
+	router := setupRouter(app)
+
+	upload := func(header string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := newUploadRequest(
+			`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+			`{"file": ["variables.file"]}`,
+			map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+		)
+		if header != "" {
+			request.Header.Add("X-GraphQL-CSRF", header)
+		}
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := upload("")
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusBadRequest)
+	}
+	expected := `{"errors":[{"message":"potential CSRF request (multipart/form-data requests must set the X-GraphQL-CSRF header)"}]}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+
+	expected = `{"data":{"singleUpload":{"size":12}}}`
+	if body := upload("1").Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+
+	// JSON requests require a preflight anyway
+	recorder = httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+	if body := recorder.Body.String(); body != `{"data":{"hello":"world"}}` {
+		t.Errorf("Response incorrect. Found %s", body)
+	}
+}
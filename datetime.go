@@ -0,0 +1,59 @@
+package graphqlgin
+
+import (
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// Parses an RFC3339 timestamp, returning nil for anything else so graphql-go
+// reports the value as invalid for the type.
+func parseDateTime(value interface{}) interface{} {
+	switch value := value.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t
+		}
+	case *string:
+		if value != nil {
+			return parseDateTime(*value)
+		}
+	case time.Time:
+		return value
+	case *time.Time:
+		if value != nil {
+			return *value
+		}
+	}
+	return nil
+}
+
+// GraphQL scalar of RFC3339 timestamps, i.e. `2021-06-01T12:00:00Z`. Resolvers
+// return `time.Time` or `*time.Time` values, while arguments and variables are
+// passed to resolvers as `time.Time`. Register it with `AppendTypes` or use it
+// in the schema like any other type.
+var DateTimeType = graphql.NewScalar(
+	graphql.ScalarConfig{
+		Name:        "DateTime",
+		Description: "RFC3339 timestamp scalar",
+		Serialize: func(value interface{}) interface{} {
+			switch value := value.(type) {
+			case time.Time:
+				return value.Format(time.RFC3339Nano)
+			case *time.Time:
+				if value != nil {
+					return value.Format(time.RFC3339Nano)
+				}
+			}
+			return nil
+		},
+		ParseValue: parseDateTime,
+		ParseLiteral: func(valueAST ast.Value) interface{} {
+			if value, ok := valueAST.(*ast.StringValue); ok {
+				return parseDateTime(value.Value)
+			}
+			return nil
+		},
+	},
+)
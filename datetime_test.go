@@ -0,0 +1,98 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+var dateTimeSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"shift": &graphql.Field{
+				Type: DateTimeType,
+				Args: graphql.FieldConfigArgument{
+					"at":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(DateTimeType)},
+					"hours": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					at := p.Args["at"].(time.Time)
+					hours, _ := p.Args["hours"].(int)
+					return at.Add(time.Duration(hours) * time.Hour), nil
+				},
+			},
+			"epoch": &graphql.Field{
+				Type: DateTimeType,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					epoch := time.Unix(0, 0).UTC()
+					return &epoch, nil
+				},
+			},
+		},
+	}),
+})
+
+func TestDateTimePOST(t *testing.T) {
+	app := New(dateTimeSchema)
+	router := setupRouter(app)
+
+	for _, test := range []struct {
+		body     string
+		expected string
+	}{
+		{
+			`{"query": "{ shift(at: \"2021-06-01T12:00:00Z\", hours: 2) }"}`,
+			`{"data":{"shift":"2021-06-01T14:00:00Z"}}`,
+		},
+		{
+			`{"query": "query ($at: DateTime!) { shift(at: $at) }", "variables": {"at": "2021-06-01T12:00:00.5+02:00"}}`,
+			`{"data":{"shift":"2021-06-01T12:00:00.5+02:00"}}`,
+		},
+		{
+			`{"query": "{ epoch }"}`,
+			`{"data":{"epoch":"1970-01-01T00:00:00Z"}}`,
+		},
+	} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(test.body))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if body := recorder.Body.String(); body != test.expected {
+			t.Errorf("Response incorrect. Found %s, expected %s", body, test.expected)
+		}
+	}
+}
+
+func TestDateTimeInvalidPOST(t *testing.T) {
+	app := New(dateTimeSchema)
+	router := setupRouter(app)
+
+	for _, body := range []string{
+		`{"query": "{ shift(at: \"yesterday\") }"}`,
+		`{"query": "{ shift(at: 1622548800) }"}`,
+		`{"query": "query ($at: DateTime!) { shift(at: $at) }", "variables": {"at": "2021-06-01"}}`,
+	} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		var res formattedErrorResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Response unmarshal failed. Err: %v", err)
+		}
+		if len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "DateTime") {
+			t.Errorf("Errors of %s incorrect. Found %s", body, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package graphqlgin
+
+import (
+	"strings"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/location"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Extension key for the source of the operation an error belongs to
+const OperationSourceExtension = "operation"
+
+// Extension key for the offending source line of a syntax error with a caret
+// pointing at the error column
+const SyntaxErrorCaretExtension = "caret"
+
+// Returns the line of `requestString` at the source location `loc` followed by
+// a line with a caret under its column.
+func sourceCaret(requestString string, loc location.SourceLocation) (string, bool) {
+	lines := strings.Split(requestString, "\n")
+	if loc.Line < 1 || loc.Line > len(lines) || loc.Column < 1 {
+		return "", false
+	}
+	line := strings.TrimRight(lines[loc.Line-1], "\r")
+	return line + "\n" + strings.Repeat(" ", loc.Column-1) + "^", true
+}
+
+// Adds the offending source line with a caret to the extensions of each error
+// in `errs` that has a location.
+func annotateSyntaxErrors(requestString string, errs []gqlerrors.FormattedError) {
+	for i := range errs {
+		if len(errs[i].Locations) == 0 {
+			continue
+		}
+		caret, ok := sourceCaret(requestString, errs[i].Locations[0])
+		if !ok {
+			continue
+		}
+		if errs[i].Extensions == nil {
+			errs[i].Extensions = map[string]interface{}{}
+		}
+		errs[i].Extensions[SyntaxErrorCaretExtension] = caret
+	}
+}
+
+// Returns true if source location `a` comes before or at source location `b`.
+func locationBefore(a, b location.SourceLocation) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Column <= b.Column)
+}
+
+// Finds the source of the operation in `doc` which encloses the source location `loc`.
+func operationSourceAt(src *source.Source, doc *ast.Document, loc location.SourceLocation) (string, bool) {
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok || operation.Loc == nil {
+			continue
+		}
+		start := location.GetLocation(src, operation.Loc.Start)
+		end := location.GetLocation(src, operation.Loc.End)
+		if locationBefore(start, loc) && locationBefore(loc, end) {
+			return string(src.Body[operation.Loc.Start:operation.Loc.End]), true
+		}
+	}
+	return "", false
+}
+
+// Adds the source of the offending operation to the extensions of each error
+// in `errs`. Errors outside of any operation (i.e. in fragments) are left untouched.
+// For syntax errors, the offending line with a caret is added instead.
+func annotateOperationSource(requestString string, errs []gqlerrors.FormattedError) {
+	if len(errs) == 0 {
+		return
+	}
+	src := source.NewSource(&source.Source{
+		Body: []byte(requestString),
+		Name: "GraphQL request",
+	})
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		// syntax errors have no operation to point at, show the error position
+		annotateSyntaxErrors(requestString, errs)
+		return
+	}
+	for i := range errs {
+		if len(errs[i].Locations) == 0 {
+			continue
+		}
+		operationSource, ok := operationSourceAt(src, doc, errs[i].Locations[0])
+		if !ok {
+			continue
+		}
+		if errs[i].Extensions == nil {
+			errs[i].Extensions = map[string]interface{}{}
+		}
+		errs[i].Extensions[OperationSourceExtension] = operationSource
+	}
+}
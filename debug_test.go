@@ -0,0 +1,125 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugOperationSourcePOST(t *testing.T) {
+	app := New(schema)
+	app.Debug = true
+	router := setupRouter(app)
+
+	type errorResponse struct {
+		Errors []struct {
+			Message    string                 `json:"message"`
+			Extensions map[string]interface{} `json:"extensions"`
+		} `json:"errors"`
+	}
+
+	query := map[string]interface{}{
+		"query":         "query good { hello }\nquery bad { unknownField }",
+		"operationName": "good",
+		"variables":     map[string]interface{}{},
+	}
+	queryBody, _ := json.Marshal(query)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(queryBody))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Request failed. Code: %d", recorder.Code)
+	}
+	var res errorResponse
+	body := recorder.Body.Bytes()
+
+	// run tests
+	if err := json.Unmarshal(body, &res); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	if len(res.Errors) != 1 {
+		t.Fatalf("Errors count incorrect. Found %d, expected %d", len(res.Errors), 1)
+	}
+	operationSource, _ := res.Errors[0].Extensions[OperationSourceExtension].(string)
+	if operationSource != "query bad { unknownField }" {
+		t.Errorf("Operation source incorrect. Found %q, expected %q", operationSource, "query bad { unknownField }")
+	}
+	if strings.Contains(operationSource, "hello") {
+		t.Errorf("Operation source leaked other operations. Found %q", operationSource)
+	}
+}
+
+func TestDebugDisabledPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	query := map[string]interface{}{
+		"query":         "query bad { unknownField }",
+		"operationName": "bad",
+		"variables":     map[string]interface{}{},
+	}
+	queryBody, _ := json.Marshal(query)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(queryBody))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if strings.Contains(recorder.Body.String(), OperationSourceExtension) {
+		t.Errorf("Operation source found without debug. Body: %s", recorder.Body.String())
+	}
+}
+
+func TestSyntaxErrorLocationPOST(t *testing.T) {
+	type errorResponse struct {
+		Errors []struct {
+			Message   string `json:"message"`
+			Locations []struct {
+				Line   int `json:"line"`
+				Column int `json:"column"`
+			} `json:"locations"`
+			Extensions map[string]interface{} `json:"extensions"`
+		} `json:"errors"`
+	}
+
+	for _, debug := range []bool{false, true} {
+		app := New(schema)
+		app.Debug = debug
+		router := setupRouter(app)
+
+		queryBody, _ := json.Marshal(map[string]interface{}{
+			"query": "query {\n  hello(\n}",
+		})
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(queryBody))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		var res errorResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Response unmarshal failed. Err: %v", err)
+		}
+		if len(res.Errors) != 1 || len(res.Errors[0].Locations) != 1 {
+			t.Fatalf("Error location not found. Body: %s", recorder.Body.String())
+		}
+		if loc := res.Errors[0].Locations[0]; loc.Line != 3 || loc.Column != 1 {
+			t.Errorf("Error location incorrect. Found %d:%d, expected %d:%d", loc.Line, loc.Column, 3, 1)
+		}
+		caret, ok := res.Errors[0].Extensions[SyntaxErrorCaretExtension]
+		if debug && caret != "}\n^" {
+			t.Errorf("Caret incorrect. Found %q, expected %q", caret, "}\n^")
+		}
+		if !debug && ok {
+			t.Errorf("Caret added without debug. Found %q", caret)
+		}
+	}
+}
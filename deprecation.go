@@ -0,0 +1,38 @@
+package graphqlgin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Finds the first deprecated field in `fields` and their selections.
+func findDeprecatedField(fields []*selectedField) *selectedField {
+	for _, field := range fields {
+		if field.Definition != nil && field.Definition.DeprecationReason != "" {
+			return field
+		}
+		if deprecated := findDeprecatedField(field.Children); deprecated != nil {
+			return deprecated
+		}
+	}
+	return nil
+}
+
+// Returns an error naming the first deprecated field selected by the request.
+func (app *GraphQLApp) checkDeprecatedFields(query *queryDocument) error {
+	fields, err := query.Fields(&app.Schema)
+	if err != nil {
+		// let the execution report invalid documents
+		return nil
+	}
+	if deprecated := findDeprecatedField(fields); deprecated != nil {
+		return fmt.Errorf(
+			"field %s.%s at path %s is deprecated: %s",
+			deprecated.ParentType.Name(),
+			deprecated.Definition.Name,
+			strings.Join(deprecated.Path, "."),
+			deprecated.Definition.DeprecationReason,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+var deprecationSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+			"oldHello": &graphql.Field{
+				Type:              graphql.String,
+				DeprecationReason: "Use hello instead",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return "old world", nil
+				},
+			},
+		},
+	}),
+})
+
+func TestBlockDeprecatedFieldsPOST(t *testing.T) {
+	app := New(deprecationSchema)
+	app.BlockDeprecatedFields = true
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "query { ...greeting } fragment greeting on Query { oldHello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	body := recorder.Body.String()
+	if strings.Contains(body, "old world") {
+		t.Errorf("Deprecated field was executed. Body: %s", body)
+	}
+	if !strings.Contains(body, "Query.oldHello") || !strings.Contains(body, "Use hello instead") {
+		t.Errorf("Error does not name the field and reason. Body: %s", body)
+	}
+}
+
+func TestAllowDeprecatedFieldsPOST(t *testing.T) {
+	app := New(deprecationSchema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ oldHello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if body := recorder.Body.String(); body != `{"data":{"oldHello":"old world"}}` {
+		t.Errorf("Response incorrect. Found %s", body)
+	}
+}
@@ -0,0 +1,37 @@
+package graphqlgin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Finds the first field in `fields` and their selections nested deeper than
+// `maxDepth`, where top level fields have a depth of 1.
+func findTooDeepField(fields []*selectedField, depth int, maxDepth int) *selectedField {
+	for _, field := range fields {
+		if depth > maxDepth {
+			return field
+		}
+		if deep := findTooDeepField(field.Children, depth+1, maxDepth); deep != nil {
+			return deep
+		}
+	}
+	return nil
+}
+
+// Returns an error naming the path of the first field exceeding `MaxQueryDepth`.
+func (app *GraphQLApp) checkQueryDepth(query *queryDocument) error {
+	fields, err := query.Fields(&app.Schema)
+	if err != nil {
+		// let the execution report invalid documents
+		return nil
+	}
+	if deep := findTooDeepField(fields, 1, app.MaxQueryDepth); deep != nil {
+		return fmt.Errorf(
+			"field at path %s exceeds the maximum depth of %d",
+			strings.Join(deep.Path, "."),
+			app.MaxQueryDepth,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Returns a schema with a recursive `node` field, counting the resolved nodes.
+func newDepthSchema(resolved *int) graphql.Schema {
+	node := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Node",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{Type: graphql.String},
+		},
+	})
+	resolveNode := func(p graphql.ResolveParams) (interface{}, error) {
+		*resolved++
+		return map[string]interface{}{"name": "node"}, nil
+	}
+	node.AddFieldConfig("child", &graphql.Field{Type: node, Resolve: resolveNode})
+	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"node": &graphql.Field{Type: node, Resolve: resolveNode},
+			},
+		}),
+	})
+	return schema
+}
+
+func TestMaxQueryDepthPOST(t *testing.T) {
+	resolved := 0
+	app := New(newDepthSchema(&resolved))
+	app.MaxQueryDepth = 3
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ node { child { ...deep } } } fragment deep on Node { child { name } }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	body := recorder.Body.String()
+	if resolved != 0 {
+		t.Errorf("Resolvers of too deep query were executed. Found %d, expected %d", resolved, 0)
+	}
+	if !strings.Contains(body, "node.child.child.name") || !strings.Contains(body, "maximum depth of 3") {
+		t.Errorf("Error does not name the field path and limit. Body: %s", body)
+	}
+}
+
+func TestMaxQueryDepthAllowedPOST(t *testing.T) {
+	resolved := 0
+	app := New(newDepthSchema(&resolved))
+	app.MaxQueryDepth = 3
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ node { child { name } } }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"node":{"child":{"name":"node"}}}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestMaxQueryDepthIntrospectionPOST(t *testing.T) {
+	resolved := 0
+	app := New(newDepthSchema(&resolved))
+	app.MaxQueryDepth = 3
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ __schema { types { fields { type { ofType { ofType { name } } } } } } }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"errors":[{"message":"query too deep (field at path __schema.types.fields.type exceeds the maximum depth of 3)"}]}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestMaxQueryDepthRepeatedFragmentSpreadsPOST(t *testing.T) {
+	resolved := 0
+	app := New(newDepthSchema(&resolved))
+	app.MaxQueryDepth = 5
+	router := setupRouter(app)
+
+	// each fragment spreads the next one twice, expanding to 2^21 selections
+	query := "{ node { ...f0 } }"
+	for i := 0; i < 21; i++ {
+		query += fmt.Sprintf(" fragment f%d on Node { ...f%d ...f%d }", i, i+1, i+1)
+	}
+	query += " fragment f21 on Node { name }"
+	queryBody, _ := json.Marshal(map[string]interface{}{"query": query})
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(queryBody))
+	request.Header.Add("Content-Type", "application/json")
+
+	started := time.Now()
+	router.ServeHTTP(recorder, request)
+
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Analysis took too long. Found %s", elapsed)
+	}
+	if resolved != 0 {
+		t.Errorf("Resolvers of too large query were executed. Found %d, expected %d", resolved, 0)
+	}
+	if body := recorder.Body.String(); !strings.Contains(body, "query too large") {
+		t.Errorf("Error not found. Body: %s", body)
+	}
+}
@@ -0,0 +1,74 @@
+package graphqlgin
+
+import (
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Key for keeping the temporary files of the current request in the `*gin.Context`
+const uploadTempFilesKey = "graphqlgin.uploadTempFiles"
+
+// Uploaded file written to a temporary file by `UploadToDisk`, passed to the
+// resolvers instead of the `*multipart.FileHeader`. It reads from the start of
+// the file, and is closed and removed once the request completes.
+type UploadedFile struct {
+	*os.File
+	// Name of the file as sent by the client
+	Filename string
+	// Size of the file in bytes
+	Size int64
+	// MIME header of the file part
+	Header textproto.MIMEHeader
+}
+
+// Writes `file` to a temporary file in `UploadTempDir`, registering it for
+// removal once the request completes.
+func (app *GraphQLApp) writeUploadToDisk(c *gin.Context, file *multipart.FileHeader) (*UploadedFile, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer src.Close()
+
+	dst, err := ioutil.TempFile(app.UploadTempDir, "graphqlgin-upload-*")
+	if err != nil {
+		return nil, err
+	}
+	uploaded := &UploadedFile{
+		File:     dst,
+		Filename: file.Filename,
+		Size:     file.Size,
+		Header:   file.Header,
+	}
+	files, _ := c.Get(uploadTempFilesKey)
+	tempFiles, _ := files.([]*UploadedFile)
+	c.Set(uploadTempFilesKey, append(tempFiles, uploaded))
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return nil, err
+	}
+	if _, err := dst.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return uploaded, nil
+}
+
+// Closes and removes the temporary files of the request, both the ones written
+// by `UploadToDisk` and the ones of parts spilled to disk while parsing the form.
+// Resolvers which opened a file keep reading it until they close it.
+func removeUploadTempFiles(c *gin.Context) {
+	files, _ := c.Get(uploadTempFilesKey)
+	tempFiles, _ := files.([]*UploadedFile)
+	for _, file := range tempFiles {
+		file.Close()
+		os.Remove(file.Name())
+	}
+	if c.Request.MultipartForm != nil {
+		c.Request.MultipartForm.RemoveAll()
+	}
+}
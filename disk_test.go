@@ -0,0 +1,106 @@
+package graphqlgin
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+var diskSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+		},
+	}),
+	Mutation: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"readUpload": &graphql.Field{
+				Type: graphql.String,
+				Args: graphql.FieldConfigArgument{
+					"file": &graphql.ArgumentConfig{
+						Type: UploadType,
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					file := p.Args["file"].(*UploadedFile)
+					content, err := ioutil.ReadAll(file)
+					if err != nil {
+						return nil, err
+					}
+					return file.Filename + ": " + string(content), nil
+				},
+			},
+		},
+	}),
+})
+
+func TestUploadToDiskPOST(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphqlgin-test")
+	if err != nil {
+		t.Fatalf("Temp dir creation failed. Err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	app := New(diskSchema)
+	app.UploadToDisk = true
+	app.UploadTempDir = dir
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation ($file: Upload!) { readUpload(file: $file) }", "variables": {"file": null}}`,
+		`{"file": ["variables.file"]}`,
+		map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"readUpload":"hello.txt: Hello, World"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Errorf("Temporary files count incorrect. Found %d, expected %d", len(files), 0)
+	}
+}
+
+func TestUploadTempFilesRemovedPOST(t *testing.T) {
+	dir, err := ioutil.TempDir("", "graphqlgin-test")
+	if err != nil {
+		t.Fatalf("Temp dir creation failed. Err: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	// multipart parts beyond the memory limit are spilled to the temp dir
+	defer os.Setenv("TMPDIR", os.Getenv("TMPDIR"))
+	os.Setenv("TMPDIR", dir)
+
+	app := New(schema)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request.ParseMultipartForm(1)
+	})
+	router.POST("/", app.Handler())
+
+	for _, query := range []string{
+		`mutation ($file: Upload!) { singleUpload(file: $file) { size } }`,
+		`mutation ($file: Upload!) { singleUpload(file: $file) { unknown } }`,
+	} {
+		recorder := httptest.NewRecorder()
+		request := newUploadRequest(
+			`{"query": "`+query+`", "variables": {"file": null}}`,
+			`{"file": ["variables.file"]}`,
+			map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+		)
+
+		router.ServeHTTP(recorder, request)
+
+		if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+			t.Errorf("Temporary files count incorrect. Found %d, expected %d", len(files), 0)
+		}
+	}
+}
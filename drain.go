@@ -0,0 +1,45 @@
+package graphqlgin
+
+// Counts a new request as in flight, returning false if the app is draining.
+func (app *GraphQLApp) acquireRequest() bool {
+	app.requestsMu.Lock()
+	defer app.requestsMu.Unlock()
+	if app.draining {
+		return false
+	}
+	app.requestsInFlight++
+	return true
+}
+
+// Counts a request as done, signalling `Drain` after the last one.
+func (app *GraphQLApp) releaseRequest() {
+	app.requestsMu.Lock()
+	defer app.requestsMu.Unlock()
+	app.requestsInFlight--
+	if app.draining && app.requestsInFlight == 0 {
+		close(app.drained)
+	}
+}
+
+// Rejects new requests of the handlers with 503 while letting the requests in
+// flight finish. Returns a channel closed once no request is in flight, so
+// graceful shutdown can await them before stopping the server.
+func (app *GraphQLApp) Drain() <-chan struct{} {
+	app.requestsMu.Lock()
+	defer app.requestsMu.Unlock()
+	if !app.draining {
+		app.draining = true
+		app.drained = make(chan struct{})
+		if app.requestsInFlight == 0 {
+			close(app.drained)
+		}
+	}
+	return app.drained
+}
+
+// Returns the number of requests currently handled.
+func (app *GraphQLApp) InFlightRequests() int {
+	app.requestsMu.Lock()
+	defer app.requestsMu.Unlock()
+	return app.requestsInFlight
+}
@@ -0,0 +1,62 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDrainPOST(t *testing.T) {
+	store := &blockingStore{started: make(chan struct{}), release: make(chan struct{})}
+	app := New(schema)
+	app.UploadStore = store
+	router := setupRouter(app)
+
+	// hold a request in flight
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, newUploadRequest(
+			`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+			`{"file": ["variables.file"]}`,
+			map[string][2]string{"file": {"hold.txt", "Hello, World"}},
+		))
+		done <- recorder
+	}()
+	<-store.started
+
+	if count := app.InFlightRequests(); count != 1 {
+		t.Errorf("In flight requests incorrect. Found %d, expected %d", count, 1)
+	}
+	drained := app.Drain()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status code while draining incorrect. Found %d, expected %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+
+	select {
+	case <-drained:
+		t.Errorf("Drained before the request in flight finished")
+	default:
+	}
+
+	close(store.release)
+	expected := `{"data":{"singleUpload":{"size":12}}}`
+	if recorder := <-done; recorder.Body.String() != expected {
+		t.Errorf("Request in flight incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+	select {
+	case <-drained:
+	case <-time.After(time.Second):
+		t.Errorf("Not drained after the request in flight finished")
+	}
+	if count := app.InFlightRequests(); count != 0 {
+		t.Errorf("In flight requests incorrect. Found %d, expected %d", count, 0)
+	}
+}
@@ -0,0 +1,79 @@
+package graphqlgin
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Message replacing the resolver errors masked by `MaskErrors`
+const MaskedErrorMessage = "internal server error"
+
+// Error whose message is safe to show to clients, so `MaskErrors` keeps it
+type ClientError interface {
+	error
+	IsClientError() bool
+}
+
+// Returns the error returned by the resolver of `err`, or nil if `err` is not
+// a resolver error (i.e. a validation error).
+func resolverError(err gqlerrors.FormattedError) error {
+	if original, ok := err.OriginalError().(*gqlerrors.Error); ok {
+		return original.OriginalError
+	}
+	return nil
+}
+
+// Replaces the messages of resolver errors with `MaskedErrorMessage`, keeping
+// their path and locations. Client errors and errors with an extension code are
+// kept as is.
+func maskErrors(errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	masked := make([]gqlerrors.FormattedError, len(errs))
+	for i, err := range errs {
+		masked[i] = err
+		original := resolverError(err)
+		if original == nil || err.Extensions["code"] != nil {
+			continue
+		}
+		var clientErr ClientError
+		if errors.As(original, &clientErr) && clientErr.IsClientError() {
+			continue
+		}
+		// the copy keeps the original error for the request log
+		masked[i].Message = MaskedErrorMessage
+	}
+	return masked
+}
+
+// Suggestions of validation errors, i.e. ` Did you mean "hello"?`
+var suggestionPattern = regexp.MustCompile(` ?Did you mean [^?]*\?`)
+
+// Removes the suggestions naming schema members from the messages of errors
+// other than resolver errors.
+func suppressSuggestions(errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	suppressed := make([]gqlerrors.FormattedError, len(errs))
+	for i, err := range errs {
+		suppressed[i] = err
+		if resolverError(err) == nil {
+			// the copy keeps the original error for the request log
+			suppressed[i].Message = suggestionPattern.ReplaceAllString(err.Message, "")
+		}
+	}
+	return suppressed
+}
+
+// Restores the original message of an error masked by `MaskErrors` or stripped
+// by `SuppressSuggestions`.
+func restoreErrorMessage(err gqlerrors.FormattedError) gqlerrors.FormattedError {
+	original := err.OriginalError()
+	if original == nil || err.Message == original.Error() {
+		return err
+	}
+	masked := err.Message == MaskedErrorMessage && resolverError(err) != nil
+	suppressed := err.Message == suggestionPattern.ReplaceAllString(original.Error(), "")
+	if masked || suppressed {
+		err.Message = original.Error()
+	}
+	return err
+}
@@ -0,0 +1,205 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+var errorSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+			"user": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nil, errors.New("sql: no rows in result set")
+				},
+			},
+		},
+	}),
+})
+
+type formattedErrorResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Path       []interface{}          `json:"path"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func redactingFormatter(ctx context.Context, errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+	for i := range errs {
+		errs[i].Message = "not found"
+		errs[i].Extensions = map[string]interface{}{"code": "NOT_FOUND"}
+	}
+	return errs
+}
+
+func TestErrorFormatterPOST(t *testing.T) {
+	app := New(errorSchema)
+	app.ErrorFormatter = redactingFormatter
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello user }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	var res formattedErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Response unmarshal failed. Err: %v", err)
+	}
+	if len(res.Errors) != 1 {
+		t.Fatalf("Errors count incorrect. Found %d, expected %d", len(res.Errors), 1)
+	}
+	if res.Errors[0].Message != "not found" || res.Errors[0].Extensions["code"] != "NOT_FOUND" {
+		t.Errorf("Error not formatted. Found %+v", res.Errors[0])
+	}
+	if len(res.Errors[0].Path) != 1 || res.Errors[0].Path[0] != "user" {
+		t.Errorf("Error path not preserved. Found %v", res.Errors[0].Path)
+	}
+}
+
+func TestErrorFormatterBatchPOST(t *testing.T) {
+	app := New(errorSchema)
+	app.ErrorFormatter = redactingFormatter
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`[{"query": "{ hello }"}, {"query": "{ user }"}]`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	var res []formattedErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Response unmarshal failed. Err: %v", err)
+	}
+	if len(res) != 2 || len(res[1].Errors) != 1 || res[1].Errors[0].Message != "not found" {
+		t.Errorf("Batched errors not formatted. Body: %s", recorder.Body.String())
+	}
+}
+
+type invalidInputError struct{}
+
+func (invalidInputError) Error() string       { return "name is required" }
+func (invalidInputError) IsClientError() bool { return true }
+
+type codedError struct{}
+
+func (codedError) Error() string { return "user is banned" }
+func (codedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": "FORBIDDEN"}
+}
+
+var maskSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+			"user": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nil, errors.New("sql: no rows in result set")
+				},
+			},
+			"invalid": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nil, fmt.Errorf("validating input: %w", invalidInputError{})
+				},
+			},
+			"banned": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nil, codedError{}
+				},
+			},
+		},
+	}),
+})
+
+func TestMaskErrorsPOST(t *testing.T) {
+	for _, test := range []struct {
+		query    string
+		path     string
+		expected string
+		logged   string
+	}{
+		{"{ hello user }", "user", MaskedErrorMessage, "sql: no rows in result set"},
+		{"{ invalid }", "invalid", "validating input: name is required", "validating input: name is required"},
+		{"{ banned }", "banned", "user is banned", "user is banned"},
+		{"{ unknown }", "", `Cannot query field "unknown" on type "Query".`, `Cannot query field "unknown" on type "Query".`},
+	} {
+		logger := &requestLogger{}
+		app := New(maskSchema)
+		app.MaskErrors = true
+		app.Logger = logger
+		router := setupRouter(app)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(fmt.Sprintf(`{"query": %q}`, test.query)))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		var res formattedErrorResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Response unmarshal failed. Err: %v", err)
+		}
+		if len(res.Errors) != 1 || res.Errors[0].Message != test.expected {
+			t.Errorf("Errors of %s incorrect. Found %s, expected %s", test.query, recorder.Body.String(), test.expected)
+			continue
+		}
+		if test.path != "" && (len(res.Errors[0].Path) != 1 || res.Errors[0].Path[0] != test.path) {
+			t.Errorf("Error path of %s not preserved. Found %v", test.query, res.Errors[0].Path)
+		}
+		if errs := logger.requests[0].entry.Errors; len(errs) != 1 || errs[0].Message != test.logged {
+			t.Errorf("Logged errors of %s incorrect. Found %v, expected %s", test.query, errs, test.logged)
+		}
+	}
+}
+
+func TestSuppressSuggestionsPOST(t *testing.T) {
+	for _, suppress := range []bool{false, true} {
+		logger := &requestLogger{}
+		app := New(schema)
+		app.SuppressSuggestions = suppress
+		app.Logger = logger
+		router := setupRouter(app)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ helo }"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		var res formattedErrorResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+			t.Fatalf("Response unmarshal failed. Err: %v", err)
+		}
+		original := `Cannot query field "helo" on type "Query". Did you mean "hello"?`
+		expected := original
+		if suppress {
+			expected = `Cannot query field "helo" on type "Query".`
+		}
+		if len(res.Errors) != 1 || res.Errors[0].Message != expected {
+			t.Errorf("Errors incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+		}
+		if errs := logger.requests[0].entry.Errors; len(errs) != 1 || errs[0].Message != original {
+			t.Errorf("Logged errors incorrect. Found %v, expected %s", errs, original)
+		}
+	}
+}
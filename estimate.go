@@ -0,0 +1,34 @@
+package graphqlgin
+
+import (
+	"fmt"
+)
+
+// Estimates the number of values the response to `fields` will contain. Lists
+// are assumed to hold as many items as requested through their `first` or
+// `limit` argument, or a single item if neither is given. The estimate
+// saturates at `maxCost`.
+func estimateResultSize(fields []*selectedField) int {
+	total := 0
+	for _, field := range fields {
+		size := saturatingAdd(1, estimateResultSize(field.Children))
+		if field.Definition != nil && isListType(field.Definition.Type) {
+			size = saturatingMul(size, listMultiplier(field.Args))
+		}
+		total = saturatingAdd(total, size)
+	}
+	return total
+}
+
+// Returns an error if the estimated result size of the request exceeds `MaxEstimatedResultSize`.
+func (app *GraphQLApp) checkResultSize(query *queryDocument) error {
+	fields, err := query.Fields(&app.Schema)
+	if err != nil {
+		// let the execution report invalid documents
+		return nil
+	}
+	if size := estimateResultSize(fields); size > app.MaxEstimatedResultSize {
+		return fmt.Errorf("estimated %d result values exceeds the limit of %d", size, app.MaxEstimatedResultSize)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxEstimatedResultSizePOST(t *testing.T) {
+	app := New(complexitySchema)
+	app.MaxEstimatedResultSize = 100
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ items(first: 1000) { id name } }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	body := recorder.Body.String()
+	if strings.Contains(body, `"data"`) {
+		t.Errorf("Query was executed. Body: %s", body)
+	}
+	if !strings.Contains(body, "estimated 3000 result values") {
+		t.Errorf("Estimate error not found. Body: %s", body)
+	}
+}
+
+func TestMaxEstimatedResultSizeAllowedPOST(t *testing.T) {
+	app := New(complexitySchema)
+	app.MaxEstimatedResultSize = 100
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ items(first: 2) { id } }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if body := recorder.Body.String(); body != `{"data":{"items":[{"id":0},{"id":1}]}}` {
+		t.Errorf("Response incorrect. Found %s", body)
+	}
+}
+
+func TestMaxEstimatedResultSizeHugePageSizesPOST(t *testing.T) {
+	app := New(newNestedSchema())
+	app.MaxEstimatedResultSize = 100
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(hugeNestedQuery))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	body := recorder.Body.String()
+	if strings.Contains(body, `"data"`) {
+		t.Errorf("Query was executed. Body: %s", body)
+	}
+	if !strings.Contains(body, "estimated 2147483647 result values") {
+		t.Errorf("Estimate error not found. Body: %s", body)
+	}
+}
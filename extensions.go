@@ -0,0 +1,24 @@
+package graphqlgin
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Key of the request extensions in the resolver context
+const requestExtensionsKey contextKey = "RequestExtensions"
+
+// Returns the `extensions` sent with the operation, or nil if the request had
+// none.
+func GetRequestExtensions(ctx context.Context) map[string]interface{} {
+	extensions, _ := ctx.Value(requestExtensionsKey).(map[string]interface{})
+	return extensions
+}
+
+// Adds the extensions returned by `ResponseExtensions` to the result.
+func (app *GraphQLApp) addResponseExtensions(ctx context.Context, result *graphql.Result) {
+	for key, value := range app.ResponseExtensions(ctx, result) {
+		setResultExtension(result, key, value)
+	}
+}
@@ -0,0 +1,60 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+func TestExtensionsPOST(t *testing.T) {
+	extensionsSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"client": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return GetRequestExtensions(p.Context)["client"], nil
+					},
+				},
+			},
+		}),
+	})
+	app := New(extensionsSchema)
+	app.ResponseExtensions = func(ctx context.Context, result *graphql.Result) map[string]interface{} {
+		return map[string]interface{}{"echo": GetRequestExtensions(ctx)["client"]}
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	body := `{"query": "{ client }", "extensions": {"client": "web"}}`
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"client":"web"},"extensions":{"echo":"web"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestNoExtensionsPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"hello":"world"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
@@ -0,0 +1,115 @@
+package graphqlgin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+// Key for setting the feature flags of the current request to the context
+const featureFlagsKey contextKey = "FeatureFlags"
+
+// Function to check if a feature flag is enabled for the current request
+type FeatureFlagFn func(flag string) bool
+
+// Schema directive marking a field definition as only available when a feature
+// flag is enabled. Add it to `graphql.SchemaConfig.Directives` to declare it.
+// graphql-go field definitions can't carry applied directives, so the fields
+// annotated with `@feature(flag: ...)` are listed in `GraphQLApp.FeatureFields`,
+// which the schema printed by `SDLHandler` shows them applied to.
+var FeatureDirective = graphql.NewDirective(graphql.DirectiveConfig{
+	Name:        "feature",
+	Description: "Marks a field as only available when the feature flag is enabled.",
+	Locations:   []string{graphql.DirectiveLocationFieldDefinition},
+	Args: graphql.FieldConfigArgument{
+		"flag": &graphql.ArgumentConfig{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: "Name of the feature flag.",
+		},
+	},
+})
+
+// Returns the flag of the `@feature` directive applied to the field `field` of
+// the type `parent`, and false if the field is not annotated.
+func (app *GraphQLApp) featureFlag(parent string, field string) (string, bool) {
+	flag, ok := app.FeatureFields[parent+"."+field]
+	return flag, ok
+}
+
+// Returns the `@feature` directives applied to fields, keyed by `Type.field`,
+// if the schema declares `FeatureDirective`.
+func (app *GraphQLApp) appliedFeatureDirectives() map[string]string {
+	if app.Schema.Directive(FeatureDirective.Name) == nil {
+		return nil
+	}
+	return app.FeatureFields
+}
+
+// Returns a copy of `ctx` carrying the feature flags `enabled`, i.e. to unit
+// test resolvers calling `GetFeatureFlags`.
+func WithFeatureFlags(ctx context.Context, enabled FeatureFlagFn) context.Context {
+	return context.WithValue(
+		ctx,
+		featureFlagsKey,
+		enabled,
+	)
+}
+
+// Returns a `ContextProviderFn` that will add the feature flags of the current
+// request, as reported by `enabled`, to the context passed down to resolver functions.
+func FeatureFlagsProvider(enabled func(c *gin.Context, flag string) bool) ContextProviderFn {
+	return func(c *gin.Context, ctx context.Context) context.Context {
+		return WithFeatureFlags(ctx, func(flag string) bool {
+			return enabled(c, flag)
+		})
+	}
+}
+
+// Extracts and returns the feature flags from the context `ctx`. When none are
+// set, every flag is reported disabled.
+func GetFeatureFlags(ctx context.Context) FeatureFlagFn {
+	if enabled, ok := ctx.Value(featureFlagsKey).(FeatureFlagFn); ok {
+		return enabled
+	}
+	return func(string) bool { return false }
+}
+
+// Finds the first field in `fields` and their selections whose `@feature`
+// directive requires a disabled flag. Returns the field and its flag.
+func (app *GraphQLApp) findDisabledFeature(fields []*selectedField, enabled FeatureFlagFn) (*selectedField, string) {
+	for _, field := range fields {
+		if field.Definition != nil && field.ParentType != nil {
+			flag, ok := app.featureFlag(field.ParentType.Name(), field.Definition.Name)
+			if ok && !enabled(flag) {
+				return field, flag
+			}
+		}
+		if disabled, flag := app.findDisabledFeature(field.Children, enabled); disabled != nil {
+			return disabled, flag
+		}
+	}
+	return nil, ""
+}
+
+// Returns an error naming the first field selected by the request whose
+// `@feature` flag is disabled in the context `ctx`.
+func (app *GraphQLApp) checkFeatureFields(ctx context.Context, query *queryDocument) error {
+	fields, err := query.Fields(&app.Schema)
+	if err != nil {
+		// let the execution report invalid documents
+		return nil
+	}
+	if disabled, flag := app.findDisabledFeature(fields, GetFeatureFlags(ctx)); disabled != nil {
+		return fmt.Errorf(
+			"field %s.%s at path %s requires feature %q",
+			disabled.ParentType.Name(),
+			disabled.Definition.Name,
+			strings.Join(disabled.Path, "."),
+			flag,
+		)
+	}
+	return nil
+}
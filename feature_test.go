@@ -0,0 +1,99 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+var featureSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+			"betaHello": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return "beta world", nil
+				},
+			},
+		},
+	}),
+	Directives: append(graphql.SpecifiedDirectives, FeatureDirective),
+})
+
+func featureRequest(router *gin.Engine, flags string) string {
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello betaHello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-Features", flags)
+
+	router.ServeHTTP(recorder, request)
+	return recorder.Body.String()
+}
+
+func TestFeatureFieldsPOST(t *testing.T) {
+	app := New(featureSchema, FeatureFlagsProvider(func(c *gin.Context, flag string) bool {
+		return strings.Contains(c.GetHeader("X-Features"), flag)
+	}))
+	app.FeatureFields = map[string]string{"Query.betaHello": "beta"}
+	router := setupRouter(app)
+
+	if body := featureRequest(router, "beta"); body != `{"data":{"betaHello":"beta world","hello":"world"}}` {
+		t.Errorf("Response with flag on incorrect. Found %s", body)
+	}
+
+	body := featureRequest(router, "")
+	if strings.Contains(body, "beta world") {
+		t.Errorf("Flagged off field was executed. Body: %s", body)
+	}
+	if !strings.Contains(body, "Query.betaHello") || !strings.Contains(body, `\"beta\"`) {
+		t.Errorf("Error does not name the field and flag. Body: %s", body)
+	}
+}
+
+func TestWithFeatureFlags(t *testing.T) {
+	if GetFeatureFlags(context.Background())("beta") {
+		t.Errorf("Flag enabled without feature flags")
+	}
+	ctx := WithFeatureFlags(context.Background(), func(flag string) bool {
+		return flag == "beta"
+	})
+	if enabled := GetFeatureFlags(ctx); !enabled("beta") || enabled("other") {
+		t.Errorf("Feature flags incorrect")
+	}
+	// untyped keys of other packages don't collide with the feature flags
+	ctx = context.WithValue(context.Background(), "FeatureFlags", FeatureFlagFn(func(string) bool { return true }))
+	if GetFeatureFlags(ctx)("beta") {
+		t.Errorf("Feature flags read from an untyped key")
+	}
+}
+
+func TestFeatureDirectiveSDL(t *testing.T) {
+	app := New(featureSchema)
+	app.FeatureFields = map[string]string{"Query.betaHello": "beta"}
+	router := gin.Default()
+	router.GET("/schema", app.SDLHandler())
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/schema", nil)
+
+	router.ServeHTTP(recorder, request)
+
+	body := recorder.Body.String()
+	for _, expected := range []string{
+		"directive @feature(\n",
+		"betaHello: String @feature(flag: \"beta\")\n",
+		"hello: String\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Schema does not contain %q. Found %s", expected, body)
+		}
+	}
+}
@@ -4,5 +4,8 @@ go 1.16
 
 require (
 	github.com/gin-gonic/gin v1.7.2
-	github.com/graphql-go/graphql v0.7.9
+	github.com/gorilla/websocket v1.5.0
+	github.com/graphql-go/graphql v0.8.1
+	go.opentelemetry.io/otel v1.3.0
+	go.opentelemetry.io/otel/trace v1.3.0
 )
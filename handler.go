@@ -1,38 +1,64 @@
 package graphqlgin
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
-	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Function to update or modify the context passed down to the resolver functions
 type ContextProviderFn func(c *gin.Context, ctx context.Context) context.Context
 
+// Function to adjust the `graphql.Params` right before the query is executed
+type ParamsMutatorFn func(c *gin.Context, params *graphql.Params)
+
+// Function to reshape the errors of a result before they are sent to the client
+type ErrorFormatterFn func(ctx context.Context, errs []gqlerrors.FormattedError) []gqlerrors.FormattedError
+
+// Content type of POST requests whose body is the query string itself
+const MIMEGraphQL = "application/graphql"
+
+// Type of the context keys of this package, so they can't collide with others
+type contextKey string
+
 // Key for setting `*gin.Context` value of the current request to the context
-const GinContextKey = "GinContext"
+const ginContextKey contextKey = "GinContext"
 
-// Returns a `ContextProviderFn` that will add the current `*gin.Context` value
-// to the context passed down to resolver functions.
-func GinContextProvider(c *gin.Context, ctx context.Context) context.Context {
+// Returns a copy of `ctx` carrying the `*gin.Context` value `c`, i.e. to unit test
+// resolvers calling `GetGinContext`.
+func WithGinContext(ctx context.Context, c *gin.Context) context.Context {
 	return context.WithValue(
 		ctx,
-		GinContextKey,
+		ginContextKey,
 		c,
 	)
 }
 
+// Returns a `ContextProviderFn` that will add the current `*gin.Context` value
+// to the context passed down to resolver functions.
+func GinContextProvider(c *gin.Context, ctx context.Context) context.Context {
+	return WithGinContext(ctx, c)
+}
+
 // Extracts and returns the current `*gin.Context` value from the context `ctx`.
 func GetGinContext(ctx context.Context) *gin.Context {
-	ginContext, _ := ctx.Value(GinContextKey).(*gin.Context)
+	ginContext, _ := ctx.Value(ginContextKey).(*gin.Context)
 	return ginContext
 }
 
@@ -41,6 +67,36 @@ type GraphQLRequestParams struct {
 	RequestString  string                 `json:"query" form:"query"`
 	VariableValues map[string]interface{} `json:"variables" form:"variables"`
 	OperationName  string                 `json:"operationName" form:"operationName"`
+	Extensions     map[string]interface{} `json:"extensions" form:"extensions"`
+}
+
+// Decodes the request parameters from JSON, accepting the variables either as
+// an object or as a string of JSON encoded variables, like in GET requests.
+func (p *GraphQLRequestParams) UnmarshalJSON(data []byte) error {
+	type params GraphQLRequestParams
+	raw := struct {
+		*params
+		VariableValues json.RawMessage `json:"variables"`
+	}{params: (*params)(p)}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	p.VariableValues = nil
+	variables := bytes.TrimSpace(raw.VariableValues)
+	if len(variables) > 0 && variables[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(variables, &encoded); err != nil {
+			return err
+		}
+		if strings.TrimSpace(encoded) == "" {
+			return nil
+		}
+		variables = []byte(encoded)
+	}
+	if len(variables) == 0 {
+		return nil
+	}
+	return json.Unmarshal(variables, &p.VariableValues)
 }
 
 // GraphQL request parameters including file upload maps and operations
@@ -54,6 +110,323 @@ type GraphQLRequest struct {
 type GraphQLApp struct {
 	Schema           graphql.Schema
 	ContextProviders []ContextProviderFn
+
+	// Adds debugging information (i.e. the source of the offending operation)
+	// to the error extensions of the response. Should not be enabled in production.
+	Debug bool
+
+	// Maximum size in bytes of non-multipart (i.e. JSON) request bodies. Zero means unlimited.
+	MaxJSONBodySize int64
+
+	// Maximum size in bytes of multipart request bodies. Zero means unlimited.
+	MaxMultipartBodySize int64
+
+	// Maximum size in bytes of request bodies of any content type, applied along
+	// with the content type limits. Zero means unlimited.
+	MaxBodyBytes int64
+
+	// Computes the cost of selected fields for complexity analysis. When set, the
+	// cost of each operation is reported in the `X-Query-Cost` response header.
+	CostFn CostFn
+
+	// Maximum cost of an operation, computed with `CostFn` or `DefaultCostFn`
+	// before execution. Zero means unlimited.
+	MaxComplexity int
+
+	// Serves paths registered with `Mount` both with and without a trailing slash.
+	IgnoreTrailingSlash bool
+
+	// Treats empty string variable values as null where they are expected as an
+	// `Int`, `Float`, `Boolean` or enum, also in lists and input objects. Other
+	// scalars, like `String` or `ID`, keep their empty strings. Useful for HTML
+	// form clients that can't send nulls.
+	EmptyStringAsNull bool
+
+	// Called with the final `graphql.Params` just before execution, allowing
+	// last minute adjustments of the schema, root object or context.
+	ParamsMutator ParamsMutatorFn
+
+	// Storage backend for uploaded files. When set, each uploaded file is stored
+	// and the stored value is passed to the resolvers instead of the file header.
+	UploadStore UploadStore
+
+	// Responds to failed uploads with 400 for client faults and 500 for server
+	// faults instead of 200.
+	UploadErrorStatus bool
+
+	// Maximum duration each context provider may take. Providers get a context
+	// cancelled once it expires, and a request whose provider exceeds it is
+	// rejected with 503. Zero means no timeout.
+	ContextProviderTimeout time.Duration
+
+	// Adds the name of the executed operation to the `extensions` of each result,
+	// so clients can correlate results of batched requests with their operations.
+	ResultOperationName bool
+
+	// Rejects operations selecting fields marked as deprecated in the schema.
+	BlockDeprecatedFields bool
+
+	// Maximum size in bytes of each uploaded file. Zero means unlimited.
+	MaxUploadSize int64
+
+	// Maximum total size in bytes of all files uploaded in one request. Zero means unlimited.
+	MaxTotalUploadSize int64
+
+	// Maximum number of values the response is estimated to hold, computed from
+	// the query before execution. Zero means unlimited.
+	MaxEstimatedResultSize int
+
+	// Responds with 201 instead of 200 when a resolver sets the `Location`
+	// response header, i.e. with `SetLocation`.
+	CreatedOnLocation bool
+
+	// Accepts multipart requests without the operations and map fields, binding
+	// the query and JSON encoded variables from separate fields and setting each
+	// file to the variable named by its form key.
+	UploadsWithoutMap bool
+
+	// Called with the errors of each result before the response is written,
+	// i.e. to add error codes or redact internal messages.
+	ErrorFormatter ErrorFormatterFn
+
+	// Replaces the messages of resolver errors with `MaskedErrorMessage` after
+	// the `ErrorFormatter`, unless the error is a `ClientError` or has a `code`
+	// extension. The `Logger` still gets the original messages.
+	MaskErrors bool
+
+	// Removes the "Did you mean ...?" suggestions from validation errors, so they
+	// don't reveal the schema, i.e. when introspection is disabled. The `Logger`
+	// still gets the original messages.
+	SuppressSuggestions bool
+
+	// Fields annotated with the `@feature(flag: ...)` directive, mapped from
+	// `Type.field` to their flag. Queries selecting a field whose flag is
+	// disabled for the request are rejected. See `FeatureDirective` and
+	// `FeatureFlagsProvider`.
+	FeatureFields map[string]string
+
+	// Chooses the response status code of executed results, i.e.
+	// `DefaultStatusCodeFn`. When nil, results are returned with 200.
+	StatusCodeFn StatusCodeFn
+
+	// Detects uploaded files of identical content by their hash and passes the
+	// same value, stored once by the `UploadStore`, for all of them.
+	DeduplicateUploads bool
+
+	// Called with the recovered value and stack of a panic during the execution
+	// of an operation, which is replied with an internal error.
+	PanicHandler PanicHandlerFn
+
+	// Maximum duration of a request. The context passed down to the resolvers
+	// is cancelled when it expires. Zero means unlimited.
+	RequestTimeout time.Duration
+
+	// Maximum number of concurrently open subscription connections. Excess
+	// connections are closed with `SubscriptionRetryCloseCode`. Zero means unlimited.
+	MaxSubscriptionConnections int
+
+	// Upper bound of the random delay before a new subscription connection is
+	// started, so clients reconnecting at once don't all start together.
+	SubscriptionStartupJitter time.Duration
+
+	// Runs the context providers concurrently with parsing and validating the
+	// query, joining before execution. Valid queries are then executed without
+	// being parsed again, so the init, parse and validation hooks of schema
+	// extensions are not called for them. With a `ParamsMutator` or
+	// `OnBeforeExecute` hook, the providers still run concurrently but the
+	// queries are parsed and validated again by `graphql.Do`.
+	ConcurrentContextProviders bool
+
+	// Version of the schema reported by `SchemaVersionHandler`
+	SchemaVersion string
+
+	// Enables automatic persisted queries, storing the queries sent with their
+	// hash in `extensions.persistedQuery`, i.e. `NewLRUPersistedQueryCache(1000)`.
+	// GET requests send the extensions JSON encoded in the `extensions` parameter.
+	PersistedQueryCache PersistedQueryCache
+
+	// Sets the `Cache-Control`, `Vary` and `ETag` headers of responses.
+	// Successful queries are cached privately for the lowest age declared with
+	// `SetCacheMaxAge`, and requests with a matching `If-None-Match` header get
+	// a 304 reply. Other responses are sent with `Cache-Control: no-store`.
+	CacheControl bool
+
+	// Maximum age of query results when `CacheControl` is enabled, which the
+	// resolvers may lower with `SetCacheMaxAge`. Zero means revalidate.
+	DefaultCacheMaxAge time.Duration
+
+	// Lets shared caches store the query results when `CacheControl` is
+	// enabled. Only enable it if the results don't depend on the user, as they
+	// are cached privately by default.
+	PublicCache bool
+
+	// Vetted queries by the SHA-256 hex digest sent in the persisted query
+	// extension. Requests with only a known hash run its query.
+	TrustedQueries map[string]string
+
+	// Only runs the `TrustedQueries`, ignoring the query text of requests and
+	// rejecting unknown hashes, so clients can't run arbitrary operations.
+	TrustedQueriesOnly bool
+
+	// Number of times a failed `UploadStore.Put` is retried before the upload
+	// fails, waiting `UploadStoreRetryBackoff`, doubled after each retry, in between.
+	UploadStoreRetries      int
+	UploadStoreRetryBackoff time.Duration
+
+	// Response status code for requests rejected by a `ContextProviderWithError`.
+	// Zero means 200.
+	ContextProviderErrorStatus int
+
+	// Compresses responses of at least `CompressionMinSize` bytes with gzip or
+	// deflate, as accepted by the client.
+	Compress           bool
+	CompressionMinSize int
+
+	// Serializes the fields of response objects in the order they were selected
+	// by the query, instead of sorted by name.
+	OrderedFields bool
+
+	// Receives the name, type, error state and duration of each executed operation.
+	MetricsRecorder MetricsRecorder
+
+	// Creates an OpenTelemetry span for each executed operation, recording the
+	// errors of the result as span events. See `TraceFieldResolvers` for field spans.
+	Tracer trace.Tracer
+
+	// Maximum number of multipart requests processed concurrently per client,
+	// as identified by `UploadLimitKey` (the client IP by default). Excess
+	// requests are rejected with 429. Zero means unlimited.
+	MaxConcurrentUploads int
+	UploadLimitKey       UploadLimitKeyFn
+
+	// Receives a structured log entry for each request, with the operation,
+	// timing and errors.
+	Logger Logger
+
+	// Receives a one line summary of each request, with the variable values
+	// redacted.
+	SummaryLogger SummaryLogger
+
+	// Maximum nesting depth of the selections of an operation, counting fields
+	// selected through fragments. Zero means unlimited.
+	MaxQueryDepth int
+
+	// Grows variable lists with nulls when the upload map targets an index past
+	// their end, instead of rejecting the request.
+	GrowUploadLists bool
+
+	// Rejects operations selecting the `__schema` or `__type` introspection
+	// fields, also through fragments. `__typename` is always allowed.
+	DisableIntrospection bool
+
+	// Number of parsed and validated query documents kept in an LRU cache, so
+	// repeated queries skip parsing and validation. Zero disables the cache.
+	QueryCacheSize int
+
+	// Creates the root object of each request, passed to the root resolvers as
+	// their source. When nil, the root object is empty.
+	RootObjectProvider RootObjectProviderFn
+
+	// Writes each uploaded file to a temporary file, passing an `*UploadedFile`
+	// to the resolvers instead of the `*multipart.FileHeader`. The temporary
+	// files are removed once the request completes. Ignored with an `UploadStore`.
+	UploadToDisk bool
+
+	// Directory of the temporary files of `UploadToDisk`. When empty, the
+	// default directory for temporary files is used.
+	UploadTempDir string
+
+	// MIME types accepted for uploaded files, which may contain wildcard
+	// subtypes like `image/*`. When empty, files of any type are accepted.
+	AllowedUploadMIMETypes []string
+
+	// Checks `AllowedUploadMIMETypes` against the type sniffed from the first
+	// 512 bytes of each file instead of the content type sent by the client.
+	SniffUploadMIMETypes bool
+
+	// Rejects POST requests with a content type allowed for HTML forms, like
+	// multipart uploads, unless they set the `CSRFPreventionHeader`, so they
+	// can't be sent cross-site without a CORS preflight.
+	CSRFPrevention bool
+
+	// Header required by `CSRFPrevention`. When empty,
+	// `DefaultCSRFPreventionHeader` is required.
+	CSRFPreventionHeader string
+
+	// Consulted before executing each operation, rejecting the denied ones with
+	// 429. See `NewIPRateLimiter` for a limiter by client IP.
+	RateLimiter RateLimiter
+
+	// Called with the params of each operation right before it is executed,
+	// after the context providers and `ParamsMutator`, i.e. to add default
+	// variables.
+	OnBeforeExecute func(ctx context.Context, params *graphql.Params)
+
+	// Called with the result of each operation before it is serialized, i.e. to
+	// add extensions.
+	OnAfterExecute func(ctx context.Context, result *graphql.Result)
+
+	// Returns extensions to add to the result of each operation, i.e. tracing
+	// timings. The request extensions are available with `GetRequestExtensions`.
+	ResponseExtensions func(ctx context.Context, result *graphql.Result) map[string]interface{}
+
+	// Writes the JSON responses instead of `c.JSON`, i.e. a `json.Encoder` with
+	// HTML escaping disabled. The content type is set by the handler.
+	ResponseEncoder func(w io.Writer, v interface{}) error
+
+	// Indents the JSON responses, which is also done for requests with the
+	// `pretty=1` query parameter. Ignored with a `ResponseEncoder`.
+	PrettyResponse bool
+
+	// Adds the resolver timings of each operation to the `tracing` extension of
+	// the result in the Apollo tracing format. The timings are recorded by a
+	// graphql-go extension added to a copy of the schema, so the schema itself
+	// is left untouched.
+	TracingEnabled bool
+
+	// Readiness checks run by `HealthHandler` by name
+	HealthChecks map[string]HealthCheckFn
+
+	// Writes the responses of single operations instead of the handler, i.e. to
+	// wrap the result in a custom envelope. The status code is set beforehand.
+	// Error replies and batches are written as usual.
+	ResponseWriter func(c *gin.Context, result *graphql.Result)
+
+	// number of open subscription connections
+	subscriptionConnections int32
+
+	// closed by `CloseSubscriptions` to shut down subscription connections
+	subscriptionsMu     sync.Mutex
+	subscriptionsClosed chan struct{}
+
+	// number of multipart requests in progress by `UploadLimitKey`
+	uploadsMu         sync.Mutex
+	uploadsInProgress map[string]int
+
+	// requests in progress, and whether new requests are rejected by `Drain`
+	requestsMu       sync.Mutex
+	requestsInFlight int
+	draining         bool
+	drained          chan struct{}
+
+	// context providers registered by name, in insertion order
+	namedProviders []namedContextProvider
+
+	// cache of parsed and validated documents, created on first use
+	queryCacheOnce sync.Once
+	queryCache     *documentCache
+
+	// schema with the extension of `TracingEnabled`, created on first use
+	apolloTracingOnce   sync.Once
+	apolloTracingSchema graphql.Schema
+
+	// resolver middlewares of `WrapResolvers`, `RecoverFieldPanics` and
+	// `TraceFieldResolvers`
+	resolverMiddlewares []ResolverMiddleware
+	recoverFieldPanics  bool
+	traceFieldResolvers bool
+	// copy of the schema with the resolvers wrapped in the middlewares
+	resolverSchema graphql.Schema
 }
 
 // GraphQL scalar to represent file upload variable
@@ -65,28 +438,83 @@ var UploadType = graphql.NewScalar(
 			// value will be set by resolver, no need to process
 			return value
 		},
+		// only accept values injected from the multipart map
+		ParseValue: func(value interface{}) interface{} {
+			switch value := value.(type) {
+			case *multipart.FileHeader, *UploadedFile:
+				return value
+			case storedUpload:
+				return value.value
+			}
+			return nil
+		},
+		// uploads can't be sent inline in the query
+		ParseLiteral: func(valueAST ast.Value) interface{} {
+			return nil
+		},
 	},
 )
 
 // Constructs a new GraphQL app
 func New(schema graphql.Schema, contextProviders ...ContextProviderFn) *GraphQLApp {
-	contextProviderFns := []ContextProviderFn{GinContextProvider}
-	contextProviderFns = append(contextProviderFns, contextProviders...)
-	schema.AppendType(UploadType)
-	return &GraphQLApp{
-		Schema:           schema,
-		ContextProviders: contextProviderFns,
+	return NewWithOptions(schema, WithContextProvider(contextProviders...))
+}
+
+// Constructs a new GraphQL app, returning an error if the schema lacks a query
+// type with at least one field, which graphql-go requires to execute requests.
+func NewWithError(schema graphql.Schema, contextProviders ...ContextProviderFn) (*GraphQLApp, error) {
+	return NewE(schema, WithContextProvider(contextProviders...))
+}
+
+// Constructs a new GraphQL app configured by `opts`, returning an error instead
+// of a broken app if the schema is invalid, i.e. the zero value left by ignoring
+// the error of `graphql.NewSchema`.
+func NewE(schema graphql.Schema, opts ...Option) (*GraphQLApp, error) {
+	query := schema.QueryType()
+	if query == nil {
+		return nil, fmt.Errorf("schema has no query type")
+	}
+	if len(query.Fields()) == 0 {
+		return nil, fmt.Errorf("query type %s has no fields", query.Name())
 	}
+	// validate the simplest query, which fails if the schema is incomplete
+	doc, err := parseQuery("{ __typename }")
+	if err != nil {
+		return nil, err
+	}
+	if result := graphql.ValidateDocument(&schema, doc, nil); !result.IsValid {
+		return nil, fmt.Errorf("invalid schema: %s", result.Errors[0].Message)
+	}
+	return NewWithOptions(schema, opts...), nil
 }
 
-// Sets leaf object value v in the map m represented by path string.
-func set(v interface{}, m interface{}, path string) error {
+// Checks if a path segment is a list index, i.e. consists of digits only.
+func isDigits(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	for _, r := range segment {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Sets leaf object value v in the map m represented by path string. The path
+// may walk objects and lists nested to any depth, null objects on the way are
+// created. A null root `m` is rejected with an `*UploadError`. Indexes past the
+// end of a list grow the list with nulls if `grow` is true, and are an error
+// otherwise.
+func set(v interface{}, m interface{}, path string, grow bool) error {
 	var parts []interface{}
-	for _, p := range strings.Split(path, ".") {
-		if isNumber, err := regexp.MatchString(`\d+`, p); err != nil {
-			return err
-		} else if isNumber {
-			index, _ := strconv.Atoi(p)
+	names := strings.Split(path, ".")
+	for _, p := range names {
+		if isDigits(p) {
+			index, err := strconv.Atoi(p)
+			if err != nil {
+				return fmt.Errorf("invalid index %s in path %s", p, path)
+			}
 			parts = append(parts, index)
 		} else {
 			parts = append(parts, p)
@@ -98,21 +526,58 @@ func set(v interface{}, m interface{}, path string) error {
 	if len(parts) >= 1 && parts[0] != "variables" {
 		return fmt.Errorf("first part of path is supposed to be variables")
 	}
+	// the root can't be replaced, so it must be an allocated object
+	if root, ok := m.(map[string]interface{}); m == nil || (ok && root == nil) {
+		return uploadClientError("could not set variable", fmt.Errorf("variables of path %s are null", path))
+	}
+	// container of m and the key of m in it, to replace created or grown values
+	var parent interface{}
+	var parentKey interface{}
+	replace := func(value interface{}) {
+		switch key := parentKey.(type) {
+		case string:
+			parent.(map[string]interface{})[key] = value
+		case int:
+			parent.([]interface{})[key] = value
+		}
+	}
 	// skip the first part as it is supposed to be variables
 	for i, p := range parts[1:] {
 		last := i+2 == len(parts)
+		current := strings.Join(names[:i+1], ".")
 		switch idx := p.(type) {
 		case string:
+			object, ok := m.(map[string]interface{})
+			if !ok && m == nil && parent != nil {
+				object, ok = map[string]interface{}{}, true
+				replace(object)
+			}
+			if !ok {
+				return fmt.Errorf("%s is not an object", current)
+			}
 			if last {
-				m.(map[string]interface{})[idx] = v
+				object[idx] = v
 			} else {
-				m = m.(map[string]interface{})[idx]
+				parent, parentKey, m = object, idx, object[idx]
 			}
 		case int:
+			list, ok := m.([]interface{})
+			if !ok && !(grow && m == nil && parent != nil) {
+				return fmt.Errorf("%s is not a list", current)
+			}
+			if idx >= len(list) {
+				if !grow {
+					return fmt.Errorf("index %d is out of range of %s with length %d", idx, current, len(list))
+				}
+				grown := make([]interface{}, idx+1)
+				copy(grown, list)
+				list = grown
+				replace(list)
+			}
 			if last {
-				m.([]interface{})[idx] = v
+				list[idx] = v
 			} else {
-				m = m.([]interface{})[idx]
+				parent, parentKey, m = list, idx, list[idx]
 			}
 		}
 	}
@@ -121,134 +586,553 @@ func set(v interface{}, m interface{}, path string) error {
 
 // Shorthand function to construct a graphql error reply
 func graphqlErrorReply(message string, err error) map[string]interface{} {
+	return graphqlErrorReplyWithExtensions(message, err, nil)
+}
+
+// Shorthand function to construct a graphql error reply with error extensions
+func graphqlErrorReplyWithExtensions(message string, err error, extensions map[string]interface{}) map[string]interface{} {
+	graphqlError := map[string]interface{}{
+		"message": fmt.Sprintf(
+			"%s (%s)",
+			message,
+			err,
+		),
+	}
+	if len(extensions) > 0 {
+		graphqlError["extensions"] = extensions
+	}
 	return map[string]interface{}{
 		"errors": []map[string]interface{}{
-			{
-				"message": fmt.Sprintf(
-					"%s (%s)",
-					message,
-					err,
-				),
-			},
+			graphqlError,
 		},
 	}
 }
 
+// Binds the parameters of a single operation request. The body of
+// `application/graphql` requests is taken as the query string.
+func bindRequest(c *gin.Context, graphqlRequest *GraphQLRequest) error {
+	if c.Request.Method == "POST" && c.ContentType() == MIMEGraphQL {
+		body, err := ioutil.ReadAll(c.Request.Body)
+		if err != nil {
+			return err
+		}
+		graphqlRequest.RequestString = string(body)
+		return nil
+	}
+	if err := c.ShouldBind(graphqlRequest); err != io.EOF {
+		return err
+	}
+	// an empty body is reported as a missing query
+	return nil
+}
+
 // Factory function to create `gin.HandlerFunc` for the GraphQL application.
 //
 // Each `contextProviders` will be called before running `graphql.Do` to generate/construct
 // the context, and this context will be passed down to the resolver by `graphql.Do`
 // function. Any context provider added before or with this function will be executed
-// sequentially for each request.
+// sequentially for each request. Providers passed to this function only apply to the
+// returned handler, so the app can safely be used for several routes.
 func (app *GraphQLApp) Handler(contextProviders ...ContextProviderFn) gin.HandlerFunc {
-	// Add any additional context provided passed to the handler factory
-	app.ContextProviders = append(app.ContextProviders, contextProviders...)
+	// Combine the app providers with the ones passed to the handler factory
+	// without modifying the app
+	providers := app.handlerProviders(contextProviders)
 
 	return func(c *gin.Context) {
-		// collect graphql request parameters
-		var graphqlRequest GraphQLRequest
-		if err := c.ShouldBind(&graphqlRequest); err != nil {
-			c.AbortWithError(http.StatusInternalServerError, err)
-		}
-
-		// TODO: parse operations and map if provided
-		if len(graphqlRequest.MapString) > 0 && len(graphqlRequest.OperationsString) > 0 {
-			// unmarshal graphql operations
-			var graphqlOperations GraphQLRequestParams
-			if err := json.Unmarshal([]byte(graphqlRequest.OperationsString), &graphqlOperations); err != nil {
-				// Reply with an error
-				c.JSON(
-					http.StatusOK,
-					graphqlErrorReply("invalid operations string", err),
+		// compress the response once it is complete
+		if app.Compress {
+			defer app.compressResponse(c)()
+		}
+
+		// log the request once it is complete
+		ctx := c.Request.Context()
+		var queries []*queryDocument
+		if app.Logger != nil || app.SummaryLogger != nil {
+			started := time.Now()
+			defer func() {
+				reply, _ := c.Get(replyKey)
+				if app.Logger != nil {
+					app.logRequest(c, ctx, started, queries, reply)
+				}
+				if app.SummaryLogger != nil {
+					app.logSummary(c, ctx, started, queries, reply, c.Writer.Status())
+				}
+			}()
+		}
+
+		// reject new requests once draining
+		if !app.acquireRequest() {
+			app.respond(
+				c,
+				http.StatusServiceUnavailable,
+				graphqlErrorReply("request rejected", fmt.Errorf("server shutting down")),
+			)
+			return
+		}
+		defer app.releaseRequest()
+
+		// reject requests which may have been sent cross-site
+		if app.CSRFPrevention {
+			if err := app.checkCSRF(c); err != nil {
+				app.respond(
+					c,
+					http.StatusBadRequest,
+					graphqlErrorReply("potential CSRF request", err),
 				)
 				return
 			}
+		}
+
+		// enforce request body size limits
+		if !app.limitRequestBody(c) {
+			return
+		}
+
+		// remove the temporary files of uploads once the request completes, also
+		// when the operation failed
+		if c.ContentType() == gin.MIMEMultipartPOSTForm {
+			defer removeUploadTempFiles(c)
+		}
+
+		// limit concurrent uploads of the client
+		if app.MaxConcurrentUploads > 0 && c.ContentType() == gin.MIMEMultipartPOSTForm {
+			key := app.uploadLimitKey(c)
+			if !app.acquireUploadSlot(key) {
+				app.respond(
+					c,
+					http.StatusTooManyRequests,
+					graphqlErrorReply("too many concurrent uploads", fmt.Errorf("limit of %d concurrent uploads reached", app.MaxConcurrentUploads)),
+				)
+				return
+			}
+			defer app.releaseUploadSlot(key)
+		}
+
+		// detect batched operations
+		batch, isBatch, err := readBatch(c)
+		if isBodyTooLarge(err) {
+			app.respond(
+				c,
+				http.StatusRequestEntityTooLarge,
+				graphqlErrorReply("request body too large", err),
+			)
+			return
+		} else if err != nil {
+			app.respond(
+				c,
+				http.StatusBadRequest,
+				graphqlErrorReply("invalid batch request", err),
+			)
+			return
+		}
 
-			// unmarshal upload/variable map
-			variableMap := map[string][]string{}
-			if err := json.Unmarshal([]byte(graphqlRequest.MapString), &variableMap); err != nil {
-				// Reply with an error
-				c.JSON(
-					http.StatusOK,
-					graphqlErrorReply("invalid map string", err),
+		var graphqlRequest GraphQLRequest
+		if !isBatch {
+			// collect graphql request parameters
+			if err := bindRequest(c, &graphqlRequest); isBodyTooLarge(err) {
+				app.respond(
+					c,
+					http.StatusRequestEntityTooLarge,
+					graphqlErrorReply("request body too large", err),
+				)
+				return
+			} else if err != nil {
+				app.respond(
+					c,
+					http.StatusBadRequest,
+					graphqlErrorReply("invalid request body", err),
 				)
 				return
 			}
 
-			// collect form data from variable map
-			uploads := map[*multipart.FileHeader][]string{}
-			variables := map[string][]string{}
-			for key, path := range variableMap {
-				if value, ok := c.GetPostForm(key); ok {
-					// this is a plain variable, not a file upload
-					variables[value] = path
-				} else if fileHeader, err := c.FormFile(key); err != nil {
-					// file upload error
-					c.JSON(
-						http.StatusOK,
-						graphqlErrorReply("invalid file upload", err),
+			// parse operations and map if provided
+			if len(graphqlRequest.MapString) > 0 && len(graphqlRequest.OperationsString) > 0 {
+				if err := app.processUploads(c, &graphqlRequest); err != nil {
+					app.respond(
+						c,
+						app.uploadErrorStatus(err),
+						graphqlErrorReplyWithExtensions(err.Message, err.Err, err.Extensions()),
+					)
+					return
+				}
+			} else if app.UploadsWithoutMap && c.ContentType() == gin.MIMEMultipartPOSTForm {
+				if err := app.processUploadsWithoutMap(c, &graphqlRequest); err != nil {
+					app.respond(
+						c,
+						app.uploadErrorStatus(err),
+						graphqlErrorReplyWithExtensions(err.Message, err.Err, err.Extensions()),
 					)
 					return
-				} else if fileHeader != nil {
-					// we found a file upload, collect the header
-					uploads[fileHeader] = path
 				}
 			}
+		}
 
-			// update graphql request data
-			graphqlRequest.RequestString = graphqlOperations.RequestString
-			graphqlRequest.OperationName = graphqlOperations.OperationName
-			graphqlRequest.VariableValues = graphqlOperations.VariableValues
-
-			// set found form values to request variable values
-			for value, paths := range variables {
-				for _, path := range paths {
-					if err := set(value, graphqlRequest.VariableValues, path); err != nil {
-						c.JSON(
-							http.StatusOK,
-							graphqlErrorReply("could not set variable", err),
-						)
-						return
-					}
-				}
+		// cancel the resolvers when the client disconnects, and bound the
+		// execution time of the request
+		ctx = c.Request.Context()
+		if app.RequestTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, app.RequestTimeout)
+			defer cancel()
+		}
+
+		// pass the uploaded files to the resolvers
+		if files, ok := c.Get(uploadedFilesKey); ok {
+			ctx = context.WithValue(ctx, uploadedFilesContextKey, files)
+		}
+
+		// collect the query documents of the operations
+		if isBatch {
+			for i := range batch {
+				queries = append(queries, &queryDocument{params: &batch[i]})
 			}
+		} else {
+			queries = append(queries, &queryDocument{params: &graphqlRequest.GraphQLRequestParams})
+		}
 
-			// set found form file uploads to request variable values
-			for file, paths := range uploads {
-				for _, path := range paths {
-					if err := set(file, graphqlRequest.VariableValues, path); err != nil {
-						c.JSON(
-							http.StatusOK,
-							graphqlErrorReply("could not set variable", err),
-						)
-						return
-					}
-				}
+		// create resolver context, keeping the request context for the loggers
+		// when the request is rejected
+		var resolverCtx context.Context
+		if app.ConcurrentContextProviders {
+			resolverCtx, err = app.provideContextWhileValidating(c, ctx, providers, queries)
+		} else {
+			resolverCtx, err = app.provideContext(c, ctx, providers)
+		}
+		var providerErr *ContextProviderError
+		if errors.As(err, &providerErr) {
+			app.respond(
+				c,
+				app.contextProviderErrorStatus(),
+				graphqlErrorReply("request rejected", providerErr.Err),
+			)
+			return
+		} else if err != nil {
+			app.respond(
+				c,
+				http.StatusServiceUnavailable,
+				graphqlErrorReply("could not create resolver context", err),
+			)
+			return
+		}
+		ctx = resolverCtx
+
+		if !isBatch {
+			status, reply := app.execute(c, ctx, queries[0])
+
+			// reply 304 if the client has the current result
+			if app.CacheControl && app.cacheResponse(c, queries[0], status, reply) {
+				return
 			}
+
+			// respond
+			app.respond(
+				c,
+				app.negotiatedStatus(c, app.createdStatus(c, status), reply),
+				reply,
+			)
+			return
+		}
+
+		// run batched operations sequentially sharing the resolver context
+		replies := make([]interface{}, len(queries))
+		for i, query := range queries {
+			_, replies[i] = app.execute(c, ctx, query)
+		}
+		if app.CacheControl {
+			c.Header("Cache-Control", "no-store")
+		}
+		app.respond(
+			c,
+			http.StatusOK,
+			replies,
+		)
+	}
+}
+
+// Loads the query of a single operation and prepares it for the checks:
+// resolves trusted and persisted queries, throttles the client, and selects
+// the operation. Returns the context carrying the request extensions, and the
+// response status code and error reply rejecting the operation, if any. Shared
+// by all transports.
+func (app *GraphQLApp) prepareQuery(c *gin.Context, ctx context.Context, query *queryDocument) (context.Context, int, interface{}) {
+	graphqlParams := query.params
+
+	// resolve trusted queries, then automatic persisted queries
+	if reply := app.loadQuery(query); reply != nil {
+		return ctx, http.StatusOK, reply
+	}
+
+	// reject requests without a query before running any check on it
+	if strings.TrimSpace(graphqlParams.RequestString) == "" {
+		return ctx, http.StatusBadRequest, graphqlErrorReply(
+			"no query provided",
+			fmt.Errorf("the request has no query string"),
+		)
+	}
+
+	// let resolvers see the request extensions
+	if graphqlParams.Extensions != nil {
+		ctx = context.WithValue(ctx, requestExtensionsKey, graphqlParams.Extensions)
+	}
+
+	// throttle clients, letting the limiter see the operation name
+	if app.RateLimiter != nil {
+		limitCtx := context.WithValue(ctx, operationNameKey, requestOperationName(query))
+		if !app.RateLimiter.Allow(limitCtx, c) {
+			return ctx, http.StatusTooManyRequests, graphqlErrorReply(
+				"rate limit exceeded",
+				fmt.Errorf("too many requests, retry later"),
+			)
 		}
+	}
+
+	// reuse the parsed and validated document of repeated queries
+	if app.QueryCacheSize > 0 {
+		app.loadCachedDocument(query)
+	}
 
-		// create resolver context
-		ctx := context.Background()
-		for _, provider := range app.ContextProviders {
-			ctx = provider(c, ctx)
+	// select the single operation of documents when no operation name is set
+	if graphqlParams.OperationName == "" {
+		if err := inferOperationName(query); err != nil {
+			return ctx, http.StatusOK, graphqlErrorReply("operation name required", err)
 		}
+	}
+	return ctx, 0, nil
+}
+
+// Runs the checks of the operation before its execution, returning the
+// response status code and error reply rejecting it, if any. Shared by all
+// transports.
+func (app *GraphQLApp) checkQuery(c *gin.Context, ctx context.Context, query *queryDocument) (int, interface{}) {
+	// coerce empty strings sent for non string variables to null
+	if app.EmptyStringAsNull {
+		coerceEmptyStrings(&app.Schema, query)
+	}
 
-		// construct graphql params
-		params := graphql.Params{
-			Schema:         app.Schema,
-			RequestString:  graphqlRequest.RequestString,
-			OperationName:  graphqlRequest.OperationName,
-			VariableValues: graphqlRequest.VariableValues,
-			Context:        ctx,
+	// reject operations too large to analyze before the checks analyzing them
+	if app.analyzesQueries() {
+		if _, err := query.Fields(&app.Schema); isTooManySelections(err) {
+			return http.StatusOK, graphqlErrorReply("query too large", err)
 		}
+	}
 
-		// process graphql query
-		result := graphql.Do(params)
+	// reject introspection
+	if app.DisableIntrospection {
+		if err := app.checkIntrospection(query); err != nil {
+			return http.StatusOK, graphqlErrorReply("introspection disabled", err)
+		}
+	}
 
-		// respond
-		c.JSON(
-			http.StatusOK,
-			result,
+	// reject too deeply nested queries
+	if app.MaxQueryDepth > 0 {
+		if err := app.checkQueryDepth(query); err != nil {
+			return http.StatusOK, graphqlErrorReply("query too deep", err)
+		}
+	}
+
+	// reject deprecated fields
+	if app.BlockDeprecatedFields {
+		if err := app.checkDeprecatedFields(query); err != nil {
+			return http.StatusOK, graphqlErrorReply("deprecated field selected", err)
+		}
+	}
+
+	// reject fields of disabled features
+	if len(app.FeatureFields) > 0 {
+		if err := app.checkFeatureFields(ctx, query); err != nil {
+			return http.StatusOK, graphqlErrorReply("feature not enabled", err)
+		}
+	}
+
+	// reject queries with too large results
+	if app.MaxEstimatedResultSize > 0 {
+		if err := app.checkResultSize(query); err != nil {
+			return http.StatusOK, graphqlErrorReply("query result too large", err)
+		}
+	}
+
+	// report the operation cost and enforce the complexity budget
+	if app.CostFn != nil || app.MaxComplexity > 0 {
+		if cost, ok := app.operationCost(query); ok {
+			if app.CostFn != nil {
+				addQueryCost(c, cost)
+			}
+			if app.MaxComplexity > 0 && cost > app.MaxComplexity {
+				return http.StatusOK, graphqlErrorReply(
+					"query too complex",
+					fmt.Errorf("cost %d exceeds the maximum complexity of %d", cost, app.MaxComplexity),
+				)
+			}
+		}
+	}
+	return 0, nil
+}
+
+// Reshapes the errors of `result` before they are sent to the client: adds the
+// operation source in debug mode, then runs the `ErrorFormatter`, and hides
+// internal details with `MaskErrors` and `SuppressSuggestions`. Shared by all
+// transports.
+func (app *GraphQLApp) formatResultErrors(ctx context.Context, requestString string, result *graphql.Result) {
+	// attach the offending operation source to validation errors
+	if app.Debug && result.Data == nil {
+		annotateOperationSource(requestString, result.Errors)
+	}
+
+	// let the user reshape the errors
+	if app.ErrorFormatter != nil && len(result.Errors) > 0 {
+		result.Errors = app.ErrorFormatter(ctx, result.Errors)
+	}
+
+	// hide internal details of resolver errors from clients
+	if app.MaskErrors && len(result.Errors) > 0 {
+		result.Errors = maskErrors(result.Errors)
+	}
+	if app.SuppressSuggestions && len(result.Errors) > 0 {
+		result.Errors = suppressSuggestions(result.Errors)
+	}
+}
+
+// Runs a single operation with the resolver context `ctx`, returning the
+// response status code and body.
+func (app *GraphQLApp) execute(c *gin.Context, ctx context.Context, query *queryDocument) (status int, reply interface{}) {
+	// turn panics escaping the executor into an error reply
+	defer app.recoverExecution(c, &status, &reply)
+
+	graphqlParams := query.params
+
+	// load the query and select its operation
+	ctx, status, reply = app.prepareQuery(c, ctx, query)
+	if reply != nil {
+		return status, reply
+	}
+
+	// only run queries over GET, since GET requests may be prefetched or cached
+	if c.Request.Method == "GET" {
+		if operationType := requestOperationType(query); operationType != "" && operationType != ast.OperationTypeQuery {
+			c.Header("Allow", "POST")
+			return http.StatusMethodNotAllowed, graphqlErrorReply(
+				"method not allowed",
+				fmt.Errorf("%s operations are not allowed over GET", operationType),
+			)
+		}
+	}
+
+	// check the operation before executing it
+	if status, reply := app.checkQuery(c, ctx, query); reply != nil {
+		return status, reply
+	}
+
+	// trace the execution, passing the span to the resolvers
+	var span trace.Span
+	if app.Tracer != nil {
+		ctx, span = app.startOperationSpan(ctx, query)
+	}
+
+	// time the resolvers in the Apollo tracing format
+	var tracing *apolloTracing
+	if app.TracingEnabled {
+		ctx, tracing = app.startApolloTracing(ctx)
+	}
+
+	// let the resolvers declare the maximum age of the result
+	var cache *cachePolicy
+	if app.CacheControl {
+		ctx, cache = app.startCachePolicy(ctx)
+	}
+
+	// construct graphql params
+	schema := app.executableSchema()
+	if tracing != nil {
+		schema = app.tracedSchema()
+	}
+	params := graphql.Params{
+		Schema:         schema,
+		RequestString:  graphqlParams.RequestString,
+		RootObject:     app.rootObject(c),
+		OperationName:  graphqlParams.OperationName,
+		VariableValues: graphqlParams.VariableValues,
+		Context:        ctx,
+	}
+
+	// let the user adjust the params
+	if app.ParamsMutator != nil {
+		app.ParamsMutator(c, &params)
+	}
+	if app.OnBeforeExecute != nil {
+		app.OnBeforeExecute(ctx, &params)
+	}
+
+	// process graphql query, skipping parsing and validation if already done
+	var started time.Time
+	if app.MetricsRecorder != nil {
+		started = time.Now()
+	}
+	var result *graphql.Result
+	if query.Valid() && app.ParamsMutator == nil && app.OnBeforeExecute == nil {
+		result = graphql.Execute(graphql.ExecuteParams{
+			Schema:        params.Schema,
+			Root:          params.RootObject,
+			AST:           query.document,
+			OperationName: params.OperationName,
+			Args:          params.VariableValues,
+			Context:       params.Context,
+		})
+	} else {
+		result = graphql.Do(params)
+	}
+	if span != nil {
+		endOperationSpan(span, result)
+	}
+	if tracing != nil {
+		setResultExtension(result, ApolloTracingExtension, tracing.extension(time.Now()))
+	}
+	if cache != nil {
+		query.cacheMaxAge = cache.get()
+	}
+	if app.MetricsRecorder != nil {
+		app.MetricsRecorder.RecordOperation(
+			requestOperationName(query),
+			requestOperationType(query),
+			len(result.Errors) > 0,
+			time.Since(started),
+		)
+	}
+
+	// report an expired request timeout instead of the partial result
+	if app.RequestTimeout > 0 && ctx.Err() == context.DeadlineExceeded {
+		return http.StatusGatewayTimeout, graphqlErrorReply(
+			"request timed out",
+			fmt.Errorf("execution exceeded the timeout of %s", app.RequestTimeout),
 		)
 	}
+
+	// reshape the errors for the client
+	app.formatResultErrors(ctx, params.RequestString, result)
+
+	// serialize the data in the selection order
+	if app.OrderedFields && result.Data != nil {
+		if fields, err := query.Fields(&app.Schema); err == nil {
+			result.Data = orderValue(result.Data, fields)
+		}
+	}
+
+	// identify the operation of the result
+	if app.ResultOperationName {
+		setResultExtension(result, OperationNameExtension, requestOperationName(query))
+	}
+
+	// let the user populate the response extensions
+	if app.ResponseExtensions != nil {
+		app.addResponseExtensions(ctx, result)
+	}
+
+	// let the user inspect or modify the result before it is serialized
+	if app.OnAfterExecute != nil {
+		app.OnAfterExecute(ctx, result)
+	}
+
+	if app.StatusCodeFn != nil {
+		return app.StatusCodeFn(result), result
+	}
+	return http.StatusOK, result
 }
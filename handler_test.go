@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -38,8 +41,7 @@ var doubleQuery = &graphql.Field{
 var ginContextQuery = &graphql.Field{
 	Type: graphql.Boolean,
 	Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-		_, ok := p.Context.Value(GinContextKey).(*gin.Context)
-		return ok, nil
+		return GetGinContext(p.Context) != nil, nil
 	},
 }
 
@@ -262,6 +264,40 @@ func TestValiablesPOST(t *testing.T) {
 	}
 }
 
+func TestMutationGET(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	queryParams := url.Values{}
+	queryParams.Add("query", "query read { hello } mutation write($file: Upload!) { singleUpload(file: $file) { size } }")
+	queryParams.Add("operationName", "write")
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/?"+queryParams.Encode(), nil)
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+	expected := `{"errors":[{"message":"method not allowed (mutation operations are not allowed over GET)"}]}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+
+	// the query of the same document is still allowed
+	queryParams.Set("operationName", "read")
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/?"+queryParams.Encode(), nil)
+
+	router.ServeHTTP(recorder, request)
+
+	expected = `{"data":{"hello":"world"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
 func TestValiablesGET(t *testing.T) {
 	app := New(schema)
 	router := setupRouter(app)
@@ -310,6 +346,48 @@ func TestFileTypeScalarAdded(t *testing.T) {
 	}
 }
 
+func TestNewWithError(t *testing.T) {
+	if _, err := NewWithError(schema); err != nil {
+		t.Errorf("Construction failed. Err: %v", err)
+	}
+
+	mutationOnly, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"singleUpload": singleFileMutation,
+			},
+		}),
+	})
+	if _, err := NewWithError(mutationOnly); err == nil {
+		t.Errorf("Construction succeeded without a query type")
+	}
+
+	emptyQuery, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name:   "Query",
+			Fields: graphql.Fields{},
+		}),
+	})
+	if _, err := NewWithError(emptyQuery); err == nil {
+		t.Errorf("Construction succeeded with an empty query type")
+	}
+}
+
+func TestNewE(t *testing.T) {
+	app, err := NewE(schema, WithMaxUploadSize(1024))
+	if err != nil {
+		t.Errorf("Construction failed. Err: %v", err)
+	} else if app.MaxUploadSize != 1024 {
+		t.Errorf("Options not applied. Found %d, expected %d", app.MaxUploadSize, 1024)
+	}
+
+	var zero graphql.Schema
+	if _, err := NewE(zero); err == nil {
+		t.Errorf("Construction succeeded with a zero value schema")
+	}
+}
+
 func TestGinContextPOST(t *testing.T) {
 	app := New(schema)
 	router := setupRouter(app)
@@ -573,6 +651,458 @@ func TestSingleFileAndValuePOST(t *testing.T) {
 	}
 }
 
+func TestExecuteHooksPOST(t *testing.T) {
+	app := New(schema)
+	app.OnBeforeExecute = func(ctx context.Context, params *graphql.Params) {
+		if params.VariableValues == nil {
+			params.VariableValues = map[string]interface{}{}
+		}
+		params.VariableValues["greet"] = true
+	}
+	app.OnAfterExecute = func(ctx context.Context, result *graphql.Result) {
+		result.Extensions = map[string]interface{}{"audited": true}
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "query ($greet: Boolean!) { hello @include(if: $greet) }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"hello":"world"},"extensions":{"audited":true}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestResponseEncoderPOST(t *testing.T) {
+	markupSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"markup": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return "<b>bold</b>", nil
+					},
+				},
+			},
+		}),
+	})
+	app := New(markupSchema)
+	app.ResponseEncoder = func(w io.Writer, v interface{}) error {
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(v)
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ markup }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"markup":"<b>bold</b>"}}` + "\n"
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/json; charset=utf-8" {
+		t.Errorf("Content type incorrect. Found %s, expected %s", contentType, "application/json; charset=utf-8")
+	}
+}
+
+func TestPrettyResponsePOST(t *testing.T) {
+	pretty := "{\n    \"data\": {\n        \"hello\": \"world\"\n    }\n}"
+	for _, test := range []struct {
+		pretty   bool
+		path     string
+		expected string
+	}{
+		{false, "/", `{"data":{"hello":"world"}}`},
+		{true, "/", pretty},
+		{false, "/?pretty=1", pretty},
+	} {
+		app := New(schema)
+		app.PrettyResponse = test.pretty
+		router := setupRouter(app)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", test.path, bytes.NewBufferString(`{"query": "{ hello }"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if body := recorder.Body.String(); body != test.expected {
+			t.Errorf("Response incorrect. Found %s, expected %s", body, test.expected)
+		}
+	}
+}
+
+func TestResponseWriterPOST(t *testing.T) {
+	app := New(schema)
+	app.ResponseWriter = func(c *gin.Context, result *graphql.Result) {
+		c.Header("X-Error-Count", fmt.Sprint(len(result.Errors)))
+		c.JSON(http.StatusAccepted, gin.H{"payload": result.Data})
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"payload":{"hello":"world"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+	if header := recorder.Header().Get("X-Error-Count"); header != "0" {
+		t.Errorf("Error count header incorrect. Found %s, expected %s", header, "0")
+	}
+
+	// error replies are written as usual
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"query":`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if body := recorder.Body.String(); strings.Contains(body, "payload") {
+		t.Errorf("Error reply written by the response writer. Found %s", body)
+	}
+}
+
+func TestVariablesFormsPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	for _, variables := range []string{
+		`{"greet": true}`,
+		`"{\"greet\": true}"`,
+	} {
+		recorder := httptest.NewRecorder()
+		body := `{"query": "query ($greet: Boolean!) { hello @include(if: $greet) }", "variables": ` + variables + `}`
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		expected := `{"data":{"hello":"world"}}`
+		if body := recorder.Body.String(); body != expected {
+			t.Errorf("Response with variables %s incorrect. Found %s, expected %s", variables, body, expected)
+		}
+	}
+}
+
+func TestInferOperationNamePOST(t *testing.T) {
+	app := New(schema)
+	var operationName string
+	app.ParamsMutator = func(c *gin.Context, params *graphql.Params) {
+		operationName = params.OperationName
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	body := `{"query": "query greeting { hello }"}`
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"hello":"world"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+	if operationName != "greeting" {
+		t.Errorf("Operation name incorrect. Found %s, expected %s", operationName, "greeting")
+	}
+
+	recorder = httptest.NewRecorder()
+	body = `{"query": "query first { hello } query second { hello }"}`
+	request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(body))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected = `{"errors":[{"message":"operation name required (document contains the operations first, second, set operationName to the one to execute)"}]}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestEmptyQueryPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	for _, body := range []string{
+		"",
+		`{"variables": {}}`,
+		`{"query": "  "}`,
+	} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Status code of body %q incorrect. Found %d, expected %d", body, recorder.Code, http.StatusBadRequest)
+		}
+		expected := `{"errors":[{"message":"no query provided (the request has no query string)"}]}`
+		if res := recorder.Body.String(); res != expected {
+			t.Errorf("Response of body %q incorrect. Found %s, expected %s", body, res, expected)
+		}
+	}
+}
+
+func TestParamsMutatorPOST(t *testing.T) {
+	rootSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"root": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						root, _ := p.Source.(map[string]interface{})
+						return root["value"], nil
+					},
+				},
+			},
+		}),
+	})
+	app := New(rootSchema)
+	app.ParamsMutator = func(c *gin.Context, params *graphql.Params) {
+		params.RootObject = map[string]interface{}{
+			"value": "from root",
+		}
+	}
+	router := setupRouter(app)
+	type rootData struct {
+		Root string `json:"root"`
+	}
+	type rootResponse struct {
+		Data rootData `json:"data"`
+	}
+
+	query := map[string]interface{}{
+		"query":         "query root { root }",
+		"operationName": "root",
+		"variables":     map[string]interface{}{},
+	}
+	queryBody, _ := json.Marshal(query)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(queryBody))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Request failed. Code: %d", recorder.Code)
+	}
+	var rootRes rootResponse
+	body := recorder.Body.Bytes()
+
+	// run tests
+	if err := json.Unmarshal(body, &rootRes); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	if rootRes.Data.Root != "from root" {
+		t.Errorf("Response incorrect. Found %s, expected %s", rootRes.Data.Root, "from root")
+	}
+}
+
+func TestMalformedBodyPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+	type errorResponse struct {
+		Errors []map[string]interface{} `json:"errors"`
+	}
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query":`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusBadRequest)
+	}
+	var errorRes errorResponse
+	body := recorder.Body.Bytes()
+
+	// run tests
+	if err := json.Unmarshal(body, &errorRes); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	if len(errorRes.Errors) != 1 {
+		t.Errorf("Errors count incorrect. Found %d, expected %d", len(errorRes.Errors), 1)
+	}
+}
+
+func TestGraphQLBodyPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{ hello }`))
+	request.Header.Add("Content-Type", "application/graphql; charset=utf-8")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"hello":"world"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestSetDigitInVariableName(t *testing.T) {
+	variables := map[string]interface{}{
+		"file1name": nil,
+		"files":     []interface{}{nil, nil},
+	}
+	if err := set("a", variables, "variables.file1name", false); err != nil {
+		t.Errorf("Set failed. Err: %v", err)
+	}
+	if err := set("b", variables, "variables.files.1", false); err != nil {
+		t.Errorf("Set failed. Err: %v", err)
+	}
+	if variables["file1name"] != "a" {
+		t.Errorf("Variable incorrect. Found %v, expected %v", variables["file1name"], "a")
+	}
+	if files := variables["files"].([]interface{}); files[1] != "b" {
+		t.Errorf("Variable incorrect. Found %v, expected %v", files[1], "b")
+	}
+}
+
+func TestSetNestedMixedPath(t *testing.T) {
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"attachments": []interface{}{
+				map[string]interface{}{"files": []interface{}{nil}},
+				map[string]interface{}{"files": []interface{}{nil, nil}},
+				nil,
+			},
+		},
+	}
+	if err := set("a", variables, "variables.input.attachments.1.files.1", false); err != nil {
+		t.Errorf("Set failed. Err: %v", err)
+	}
+	// null objects on the path are created
+	if err := set("b", variables, "variables.input.attachments.2.file", false); err != nil {
+		t.Errorf("Set failed. Err: %v", err)
+	}
+	if err := set("c", variables, "variables.input.attachments.1.files.1.name", false); err == nil {
+		t.Errorf("Set succeeded through a non object value")
+	}
+
+	attachments := variables["input"].(map[string]interface{})["attachments"].([]interface{})
+	files := attachments[1].(map[string]interface{})["files"].([]interface{})
+	if files[1] != "a" || files[0] != nil {
+		t.Errorf("Variable incorrect. Found %v, expected %v", files, []interface{}{nil, "a"})
+	}
+	if file := attachments[2].(map[string]interface{})["file"]; file != "b" {
+		t.Errorf("Variable incorrect. Found %v, expected %v", file, "b")
+	}
+}
+
+func TestSetIndexOverflow(t *testing.T) {
+	variables := map[string]interface{}{
+		"files": []interface{}{nil},
+	}
+	err := set("a", variables, "variables.files.99999999999999999999", true)
+	expected := "invalid index 99999999999999999999 in path variables.files.99999999999999999999"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Error incorrect. Found %v, expected %s", err, expected)
+	}
+	if file := variables["files"].([]interface{})[0]; file != nil {
+		t.Errorf("Variable incorrect. Found %v, expected nil", file)
+	}
+}
+
+func TestSetNullRoot(t *testing.T) {
+	var variables map[string]interface{}
+	err := set("a", variables, "variables.file", false)
+	var uploadErr *UploadError
+	if !errors.As(err, &uploadErr) || uploadErr.Category != UploadErrorClient {
+		t.Errorf("Error incorrect. Found %v, expected a client upload error", err)
+	}
+}
+
+func BenchmarkSet(b *testing.B) {
+	path := "variables.input.sections.3.attachments.2.files.1"
+	for i := 0; i < b.N; i++ {
+		variables := map[string]interface{}{}
+		if err := set("file", variables, path, true); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestDigitInUploadVariableNamePOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	operations := map[string]interface{}{
+		"query":         `mutation uploadFile ( $file1name: Upload! ) { singleUpload( file: $file1name ) { filename size } }`,
+		"operationName": "uploadFile",
+		"variables": map[string]interface{}{
+			"file1name": nil,
+		},
+	}
+	operationsBody, _ := json.Marshal(operations)
+
+	buff := bytes.NewBuffer(nil)
+	form := multipart.NewWriter(buff)
+	form.WriteField("operations", string(operationsBody))
+	form.WriteField("map", `{"0": ["variables.file1name"]}`)
+	w, _ := form.CreateFormFile("0", "hello.txt")
+	w.Write([]byte("Hello, World"))
+	form.Close()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", buff)
+	request.Header.Add("Content-Type", form.FormDataContentType())
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Request failed. Code: %d", recorder.Code)
+	}
+	expected := `{"data":{"singleUpload":{"filename":"hello.txt","size":12}}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestHandlerCalledTwicePOST(t *testing.T) {
+	calls := 0
+	app := New(schema, func(c *gin.Context, ctx context.Context) context.Context {
+		calls++
+		return ctx
+	})
+	router := setupRouter(app)
+	router.POST("/other", app.Handler())
+
+	for _, path := range []string{"/", "/other"} {
+		calls = 0
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", path, bytes.NewBufferString(`{"query": "{ hello }"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if calls != 1 {
+			t.Errorf("Provider calls incorrect for %s. Found %d, expected %d", path, calls, 1)
+		}
+	}
+	if len(app.ContextProviders) != 2 {
+		t.Errorf("App providers modified. Found %d, expected %d", len(app.ContextProviders), 2)
+	}
+}
+
 func ExampleGraphQLApp_simple_usage() {
 	// Construct graphql schema
 	schema, _ := graphql.NewSchema(graphql.SchemaConfig{
@@ -944,3 +1474,25 @@ func ExampleGraphQLApp_context_usage() {
 	// Output:
 	// {"data":{"users":[{"email":"a@b.c","name":"John"},{"email":"c@b.a","name":"Kratos"}]}}
 }
+
+func TestWithGinContextResolver(t *testing.T) {
+	userAgent := func(p graphql.ResolveParams) (interface{}, error) {
+		c := GetGinContext(p.Context)
+		if c == nil {
+			return nil, fmt.Errorf("no gin context")
+		}
+		return c.GetHeader("User-Agent"), nil
+	}
+
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request, _ = http.NewRequest("POST", "/", nil)
+	c.Request.Header.Set("User-Agent", "resolver-test")
+
+	value, err := userAgent(graphql.ResolveParams{Context: WithGinContext(context.Background(), c)})
+	if err != nil || value != "resolver-test" {
+		t.Errorf("Resolver result incorrect. Found %v (%v), expected %s", value, err, "resolver-test")
+	}
+	if _, err := userAgent(graphql.ResolveParams{Context: context.WithValue(context.Background(), "GinContext", c)}); err == nil {
+		t.Errorf("Gin context found under an untyped key")
+	}
+}
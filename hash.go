@@ -0,0 +1,97 @@
+package graphqlgin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"unicode"
+)
+
+// Collapses insignificant whitespace, commas and comments of a GraphQL query
+// string into single spaces, leaving string literals untouched.
+func normalizeQuery(query string) string {
+	var builder strings.Builder
+	runes := []rune(query)
+	pendingSpace := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '#':
+			// comments run to the end of the line
+			for i+1 < len(runes) && runes[i+1] != '\n' && runes[i+1] != '\r' {
+				i++
+			}
+			pendingSpace = builder.Len() > 0
+			continue
+		case r == ',' || unicode.IsSpace(r) || r == '\uFEFF':
+			pendingSpace = builder.Len() > 0
+			continue
+		}
+		if pendingSpace {
+			builder.WriteRune(' ')
+			pendingSpace = false
+		}
+		if r != '"' {
+			builder.WriteRune(r)
+			continue
+		}
+		// copy string literals verbatim
+		end := stringLiteralEnd(runes, i)
+		builder.WriteString(string(runes[i:end]))
+		i = end - 1
+	}
+	return builder.String()
+}
+
+// Returns the index after the string literal, or block string, starting at
+// `start`, or the end of `runes` if the literal is not terminated.
+func stringLiteralEnd(runes []rune, start int) int {
+	block := hasTripleQuote(runes, start)
+	i := start + 1
+	if block {
+		i = start + 3
+	}
+	for i < len(runes) {
+		switch {
+		case block && runes[i] == '\\' && hasTripleQuote(runes, i+1):
+			// escaped triple quote
+			i += 4
+		case block && hasTripleQuote(runes, i):
+			return i + 3
+		case !block && runes[i] == '\\':
+			i += 2
+		case !block && runes[i] == '"':
+			return i + 1
+		default:
+			i++
+		}
+	}
+	return len(runes)
+}
+
+// Checks if `runes` has three double quotes at `i`.
+func hasTripleQuote(runes []rune, i int) bool {
+	return i+2 < len(runes) && runes[i] == '"' && runes[i+1] == '"' && runes[i+2] == '"'
+}
+
+// Computes a stable hash of the request parameters.
+//
+// The query is normalized for whitespace, commas and comments, and the
+// variables are serialized with sorted keys, so equivalent requests produce the
+// same hash regardless of formatting or map ordering. The returned value is a
+// hex encoded SHA-256 digest.
+func HashRequest(params GraphQLRequestParams) (string, error) {
+	// encoding/json sorts map keys, which gives us a canonical representation
+	variables, err := json.Marshal(params.VariableValues)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.New()
+	hash.Write([]byte(normalizeQuery(params.RequestString)))
+	hash.Write([]byte{0})
+	hash.Write([]byte(params.OperationName))
+	hash.Write([]byte{0})
+	hash.Write(variables)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,77 @@
+package graphqlgin
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHashRequestVariableOrder(t *testing.T) {
+	var first, second GraphQLRequestParams
+	json.Unmarshal([]byte(`{
+		"query": "query double($value: Int) { double(value: $value) }",
+		"operationName": "double",
+		"variables": {"value": 5, "nested": {"a": 1, "b": [1, 2]}, "other": "x"}
+	}`), &first)
+	json.Unmarshal([]byte(`{
+		"query": "query double($value: Int) {\n  double(value: $value)\n}",
+		"operationName": "double",
+		"variables": {"other": "x", "nested": {"b": [1, 2], "a": 1}, "value": 5}
+	}`), &second)
+
+	firstHash, err := HashRequest(first)
+	if err != nil {
+		t.Fatalf("Hashing failed. Err: %v", err)
+	}
+	secondHash, err := HashRequest(second)
+	if err != nil {
+		t.Fatalf("Hashing failed. Err: %v", err)
+	}
+	if firstHash != secondHash {
+		t.Errorf("Hashes differ. Found %s and %s", firstHash, secondHash)
+	}
+}
+
+func TestHashRequestDiffers(t *testing.T) {
+	first := GraphQLRequestParams{
+		RequestString:  `query { hello(name: "a  b") }`,
+		VariableValues: map[string]interface{}{},
+	}
+	second := GraphQLRequestParams{
+		RequestString:  `query { hello(name: "a b") }`,
+		VariableValues: map[string]interface{}{},
+	}
+	firstHash, _ := HashRequest(first)
+	secondHash, _ := HashRequest(second)
+	if firstHash == secondHash {
+		t.Errorf("Hashes are equal for different string literals. Found %s", firstHash)
+	}
+}
+
+func TestHashRequestInsignificantTokens(t *testing.T) {
+	first := GraphQLRequestParams{
+		RequestString: `query { hello(name: "a", other: "b") double(value: 2) }`,
+	}
+	second := GraphQLRequestParams{
+		RequestString: "# greeting\nquery {\n  hello(name: \"a\" other: \"b\"), # both\n  double(value: 2)\n}",
+	}
+	firstHash, _ := HashRequest(first)
+	secondHash, _ := HashRequest(second)
+	if firstHash != secondHash {
+		t.Errorf("Hashes differ. Found %s and %s", firstHash, secondHash)
+	}
+}
+
+func TestNormalizeQueryStrings(t *testing.T) {
+	cases := map[string]string{
+		`{ hello(name: "a, # b") }`:                         `{ hello(name: "a, # b") }`,
+		`{ hello(name: "a \" # b") , }`:                     `{ hello(name: "a \" # b") }`,
+		"{ hello(name: \"\"\"a \"\" #, \\\"\"\" b\"\"\") }": "{ hello(name: \"\"\"a \"\" #, \\\"\"\" b\"\"\") }",
+		"{ hello(name: \"\"\"a\n  # b\"\"\") } # c":         "{ hello(name: \"\"\"a\n  # b\"\"\") }",
+		"{ hello(name: \"\"),,, }":                          "{ hello(name: \"\") }",
+	}
+	for query, expected := range cases {
+		if normalized := normalizeQuery(query); normalized != expected {
+			t.Errorf("Normalized query incorrect. Found %q, expected %q", normalized, expected)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package graphqlgin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Checks whether a dependency of the app (i.e. a database) is ready, returning
+// an error if it isn't
+type HealthCheckFn func(ctx context.Context) error
+
+// Body of the replies of `HealthHandler`
+type HealthStatus struct {
+	Status string            `json:"status"`
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// Returns a `gin.HandlerFunc` for liveness and readiness probes. It replies 200
+// when every `HealthChecks` passes, and 503 naming the failed checks otherwise
+// or once the app is draining. No GraphQL operation is run.
+func (app *GraphQLApp) HealthHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		errs := map[string]string{}
+		app.requestsMu.Lock()
+		if app.draining {
+			errs["drain"] = "server shutting down"
+		}
+		app.requestsMu.Unlock()
+		for name, check := range app.HealthChecks {
+			if err := check(c.Request.Context()); err != nil {
+				errs[name] = err.Error()
+			}
+		}
+
+		if len(errs) > 0 {
+			c.JSON(http.StatusServiceUnavailable, HealthStatus{Status: "unavailable", Errors: errs})
+			return
+		}
+		c.JSON(http.StatusOK, HealthStatus{Status: "ok"})
+	}
+}
@@ -0,0 +1,53 @@
+package graphqlgin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHealthHandler(t *testing.T) {
+	app := New(schema)
+	ready := true
+	app.HealthChecks = map[string]HealthCheckFn{
+		"db": func(ctx context.Context) error {
+			if !ready {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	}
+	router := gin.Default()
+	router.GET("/healthz", app.HealthHandler())
+
+	for _, test := range []struct {
+		ready    bool
+		drain    bool
+		code     int
+		expected string
+	}{
+		{true, false, http.StatusOK, `{"status":"ok"}`},
+		{false, false, http.StatusServiceUnavailable, `{"status":"unavailable","errors":{"db":"connection refused"}}`},
+		{true, true, http.StatusServiceUnavailable, `{"status":"unavailable","errors":{"drain":"server shutting down"}}`},
+	} {
+		ready = test.ready
+		if test.drain {
+			app.Drain()
+		}
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("GET", "/healthz", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.code {
+			t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, test.code)
+		}
+		if body := recorder.Body.String(); body != test.expected {
+			t.Errorf("Response incorrect. Found %s, expected %s", body, test.expected)
+		}
+	}
+}
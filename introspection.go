@@ -0,0 +1,39 @@
+package graphqlgin
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Finds the first introspection field in `fields` and their selections, except
+// `__typename` which clients rely on for normal operations.
+func findIntrospectionField(fields []*selectedField) *selectedField {
+	for _, field := range fields {
+		if field.Field.Name != nil {
+			if name := field.Field.Name.Value; name == "__schema" || name == "__type" {
+				return field
+			}
+		}
+		if introspection := findIntrospectionField(field.Children); introspection != nil {
+			return introspection
+		}
+	}
+	return nil
+}
+
+// Returns an error naming the first introspection field selected by the request.
+func (app *GraphQLApp) checkIntrospection(query *queryDocument) error {
+	fields, err := query.Fields(&app.Schema)
+	if err != nil {
+		// let the execution report invalid documents
+		return nil
+	}
+	if introspection := findIntrospectionField(fields); introspection != nil {
+		return fmt.Errorf(
+			"field %s at path %s is not allowed",
+			introspection.Field.Name.Value,
+			strings.Join(introspection.Path, "."),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDisableIntrospectionPOST(t *testing.T) {
+	app := New(schema)
+	app.DisableIntrospection = true
+	router := setupRouter(app)
+
+	for query, expected := range map[string]string{
+		`{ __schema { queryType { name } } }`:                                       `__schema at path __schema is not allowed`,
+		`{ ...types } fragment types on Query { __type(name: \"Query\") { name } }`: `__type at path __type is not allowed`,
+		`{ hello __typename }`:                                                      `{"data":{"__typename":"Query","hello":"world"}}`,
+	} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "`+query+`"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if body := recorder.Body.String(); !strings.Contains(body, expected) {
+			t.Errorf("Response incorrect. Found %s, expected it to contain %s", body, expected)
+		}
+	}
+}
+
+func TestIntrospectionAllowedPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ __schema { queryType { name } } }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"__schema":{"queryType":{"name":"Query"}}}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
@@ -0,0 +1,51 @@
+package graphqlgin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Returns the body size limit applicable to the request's content type, which
+// is the lower of the content type limit and `MaxBodyBytes`. Zero means unlimited.
+func (app *GraphQLApp) bodySizeLimit(c *gin.Context) int64 {
+	limit := app.MaxJSONBodySize
+	if c.ContentType() == gin.MIMEMultipartPOSTForm {
+		limit = app.MaxMultipartBodySize
+	}
+	if app.MaxBodyBytes > 0 && (limit <= 0 || app.MaxBodyBytes < limit) {
+		limit = app.MaxBodyBytes
+	}
+	return limit
+}
+
+// Checks if `err` was caused by reading past a `http.MaxBytesReader` limit.
+func isBodyTooLarge(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "http: request body too large")
+}
+
+// Rejects the request if its declared content length exceeds the body size
+// limit, otherwise caps the body reader at that limit. Returns false if the
+// request has been rejected.
+func (app *GraphQLApp) limitRequestBody(c *gin.Context) bool {
+	limit := app.bodySizeLimit(c)
+	if limit <= 0 || c.Request.Body == nil {
+		return true
+	}
+	if c.Request.ContentLength > limit {
+		app.respond(
+			c,
+			http.StatusRequestEntityTooLarge,
+			graphqlErrorReply(
+				"request body too large",
+				fmt.Errorf("%d bytes exceeds the limit of %d bytes", c.Request.ContentLength, limit),
+			),
+		)
+		return false
+	}
+	// bodies with unknown length are caught while binding
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	return true
+}
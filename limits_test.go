@@ -0,0 +1,153 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxJSONBodySizePOST(t *testing.T) {
+	app := New(schema)
+	app.MaxJSONBodySize = 64
+	app.MaxMultipartBodySize = 1 << 20
+	router := setupRouter(app)
+
+	query := map[string]interface{}{
+		"query":         "query hello { hello }",
+		"operationName": "hello",
+		"variables": map[string]interface{}{
+			"padding": strings.Repeat("x", 128),
+		},
+	}
+	queryBody, _ := json.Marshal(query)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(queryBody))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+	if !strings.Contains(recorder.Body.String(), "request body too large") {
+		t.Errorf("Error message not found. Body: %s", recorder.Body.String())
+	}
+}
+
+func TestMaxMultipartBodySizePOST(t *testing.T) {
+	app := New(schema)
+	app.MaxJSONBodySize = 64
+	app.MaxMultipartBodySize = 1 << 20
+	router := setupRouter(app)
+
+	type fileData struct {
+		Filename string `json:"filename"`
+		Size     int    `json:"size"`
+	}
+	type mutationWrapper struct {
+		Mutation fileData `json:"singleUpload"`
+	}
+	type fileResponse struct {
+		Data mutationWrapper `json:"data"`
+	}
+
+	operations := map[string]interface{}{
+		"query":         `mutation uploadFile ( $file: Upload! ) { singleUpload( file: $file ) { filename size } }`,
+		"operationName": "uploadFile",
+		"variables": map[string]interface{}{
+			"file": nil,
+		},
+	}
+	operationsBody, _ := json.Marshal(operations)
+
+	buff := bytes.NewBuffer(nil)
+	form := multipart.NewWriter(buff)
+	form.WriteField("operations", string(operationsBody))
+	form.WriteField("map", `{"file": ["variables.file"]}`)
+	w, _ := form.CreateFormFile("file", "hello.txt")
+	w.Write([]byte(strings.Repeat("x", 128)))
+	form.Close()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", buff)
+	request.Header.Add("Content-Type", form.FormDataContentType())
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Request failed. Code: %d", recorder.Code)
+	}
+	var res fileResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	if res.Data.Mutation.Size != 128 {
+		t.Errorf("File size incorrect. expected %d found %d", 128, res.Data.Mutation.Size)
+	}
+}
+
+func TestMaxMultipartBodySizeExceededPOST(t *testing.T) {
+	app := New(schema)
+	app.MaxMultipartBodySize = 256
+	router := setupRouter(app)
+
+	buff := bytes.NewBuffer(nil)
+	form := multipart.NewWriter(buff)
+	form.WriteField("operations", `{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`)
+	form.WriteField("map", `{"file": ["variables.file"]}`)
+	w, _ := form.CreateFormFile("file", "hello.txt")
+	w.Write([]byte(strings.Repeat("x", 512)))
+	form.Close()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", buff)
+	request.Header.Add("Content-Type", form.FormDataContentType())
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestMaxBodyBytesPOST(t *testing.T) {
+	app := New(schema)
+	app.MaxBodyBytes = 256
+	router := setupRouter(app)
+
+	requests := []*http.Request{
+		newUploadRequest(
+			`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+			`{"file": ["variables.file"]}`,
+			map[string][2]string{"file": {"hello.txt", strings.Repeat("x", 512)}},
+		),
+	}
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }", "variables": {"padding": "`+strings.Repeat("x", 512)+`"}}`))
+	request.Header.Add("Content-Type", "application/json")
+	requests = append(requests, request)
+
+	for _, request := range requests {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusRequestEntityTooLarge)
+		}
+		if !strings.Contains(recorder.Body.String(), "request body too large") {
+			t.Errorf("Error message not found. Body: %s", recorder.Body.String())
+		}
+	}
+
+	recorder := httptest.NewRecorder()
+	request, _ = http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+	router.ServeHTTP(recorder, request)
+	if expected := `{"data":{"hello":"world"}}`; recorder.Body.String() != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+}
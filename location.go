@@ -0,0 +1,25 @@
+package graphqlgin
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Sets the `Location` response header from a resolver, i.e. to point at a
+// resource created by a mutation. Does nothing if the context lacks a `*gin.Context`.
+func SetLocation(ctx context.Context, location string) {
+	if c := GetGinContext(ctx); c != nil {
+		c.Header("Location", location)
+	}
+}
+
+// Upgrades a successful response status to 201 when `CreatedOnLocation` is set
+// and a resolver has set the `Location` response header.
+func (app *GraphQLApp) createdStatus(c *gin.Context, status int) int {
+	if app.CreatedOnLocation && status == http.StatusOK && c.Writer.Header().Get("Location") != "" {
+		return http.StatusCreated
+	}
+	return status
+}
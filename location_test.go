@@ -0,0 +1,69 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+var locationSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+		},
+	}),
+	Mutation: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createUser": &graphql.Field{
+				Type: graphql.Int,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					SetLocation(p.Context, "/users/42")
+					return 42, nil
+				},
+			},
+		},
+	}),
+})
+
+func TestCreatedOnLocationPOST(t *testing.T) {
+	app := New(locationSchema)
+	app.CreatedOnLocation = true
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "mutation { createUser }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusCreated {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusCreated)
+	}
+	if location := recorder.Header().Get("Location"); location != "/users/42" {
+		t.Errorf("Location header incorrect. Found %s, expected %s", location, "/users/42")
+	}
+	if body := recorder.Body.String(); body != `{"data":{"createUser":42}}` {
+		t.Errorf("Response incorrect. Found %s", body)
+	}
+}
+
+func TestCreatedOnLocationQueryPOST(t *testing.T) {
+	app := New(locationSchema)
+	app.CreatedOnLocation = true
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,177 @@
+package graphqlgin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Key for keeping the reply of the current request in the `*gin.Context`
+const replyKey = "graphqlgin.reply"
+
+// Entry passed to `Logger` for each request
+type RequestLog struct {
+	// Name of the operation, comma separated for batches
+	Operation string
+	// Type of the operation (query, mutation or subscription), comma separated for batches
+	OperationType string
+	// Whether the request is a multipart file upload request
+	Upload bool
+	// Time taken to process the request
+	Duration time.Duration
+	// Errors of the reply, including errors rejecting the request
+	Errors []gqlerrors.FormattedError
+}
+
+// Structured logging hook called once per request
+type Logger interface {
+	// Called with the resolver context, or the request context when the request
+	// was rejected before the resolver context was created.
+	LogRequest(ctx context.Context, entry RequestLog)
+}
+
+// Replies with `reply` as JSON of the negotiated content type, with the
+// `ResponseEncoder` if set, or with the `ResponseWriter` for single results,
+// keeping it for the request log.
+func (app *GraphQLApp) respond(c *gin.Context, status int, reply interface{}) {
+	c.Set(replyKey, reply)
+	if result, ok := reply.(*graphql.Result); ok && app.ResponseWriter != nil {
+		c.Status(status)
+		app.ResponseWriter(c, result)
+		return
+	}
+	c.Header("Content-Type", responseContentType(c))
+	app.writeReply(c, status, reply, app.PrettyResponse || c.Query("pretty") == "1")
+}
+
+// Returns the errors of a reply, which is either a result, an error reply, or a
+// list of them for batches.
+func replyErrors(reply interface{}) []gqlerrors.FormattedError {
+	var errs []gqlerrors.FormattedError
+	switch reply := reply.(type) {
+	case *graphql.Result:
+		errs = append(errs, reply.Errors...)
+	case map[string]interface{}:
+		replyErrs, _ := reply["errors"].([]map[string]interface{})
+		for _, err := range replyErrs {
+			extensions, _ := err["extensions"].(map[string]interface{})
+			errs = append(errs, gqlerrors.FormattedError{
+				Message:    fmt.Sprint(err["message"]),
+				Extensions: extensions,
+			})
+		}
+	case []interface{}:
+		for _, item := range reply {
+			errs = append(errs, replyErrors(item)...)
+		}
+	}
+	return errs
+}
+
+// Passes the log entry of the request to the `Logger`, with the errors of `reply`.
+func (app *GraphQLApp) logRequest(c *gin.Context, ctx context.Context, started time.Time, queries []*queryDocument, reply interface{}) {
+	names := make([]string, 0, len(queries))
+	types := make([]string, 0, len(queries))
+	for _, query := range queries {
+		names = append(names, requestOperationName(query))
+		types = append(types, requestOperationType(query))
+	}
+	errs := replyErrors(reply)
+	restorePanicMessages(c, errs)
+	if app.MaskErrors || app.SuppressSuggestions {
+		for i := range errs {
+			errs[i] = restoreErrorMessage(errs[i])
+		}
+	}
+	app.Logger.LogRequest(ctx, RequestLog{
+		Operation:     strings.Join(names, ","),
+		OperationType: strings.Join(types, ","),
+		Upload:        c.ContentType() == gin.MIMEMultipartPOSTForm,
+		Duration:      time.Since(started),
+		Errors:        errs,
+	})
+}
+
+// Placeholder logged instead of the variable values
+const RedactedValue = "[REDACTED]"
+
+// Summary of a request, which never contains the variable values
+type RequestSummary struct {
+	// HTTP method of the request
+	Method string
+	// Name of the operation, comma separated for batches
+	Operation string
+	// Time taken to process the request
+	Duration time.Duration
+	// Number of errors in the reply
+	ErrorCount int
+	// Response status code
+	Status int
+	// Sorted keys of the variables
+	VariableKeys []string
+}
+
+// Returns the summary as a single log line, with the variable values redacted.
+func (s RequestSummary) String() string {
+	variables := make([]string, 0, len(s.VariableKeys))
+	for _, key := range s.VariableKeys {
+		variables = append(variables, key+"="+RedactedValue)
+	}
+	return fmt.Sprintf(
+		"method=%s operation=%q duration=%s errors=%d status=%d variables={%s}",
+		s.Method,
+		s.Operation,
+		s.Duration,
+		s.ErrorCount,
+		s.Status,
+		strings.Join(variables, " "),
+	)
+}
+
+// Summary logging hook called once per request
+type SummaryLogger interface {
+	LogSummary(ctx context.Context, summary RequestSummary)
+}
+
+// Passes the summary of the request to the `SummaryLogger`, with the errors of
+// `reply` and the response `status`.
+func (app *GraphQLApp) logSummary(c *gin.Context, ctx context.Context, started time.Time, queries []*queryDocument, reply interface{}, status int) {
+	names := make([]string, 0, len(queries))
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, query := range queries {
+		names = append(names, requestOperationName(query))
+		for key := range query.params.VariableValues {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	app.SummaryLogger.LogSummary(ctx, RequestSummary{
+		Method:       c.Request.Method,
+		Operation:    strings.Join(names, ","),
+		Duration:     time.Since(started),
+		ErrorCount:   len(replyErrors(reply)),
+		Status:       status,
+		VariableKeys: keys,
+	})
+}
+
+// Logs a subscription operation once it ends, with the errors of its results.
+func (app *GraphQLApp) logSubscription(c *gin.Context, ctx context.Context, started time.Time, query *queryDocument, errs []gqlerrors.FormattedError) {
+	reply := &graphql.Result{Errors: errs}
+	if app.Logger != nil {
+		app.logRequest(c, ctx, started, []*queryDocument{query}, reply)
+	}
+	if app.SummaryLogger != nil {
+		app.logSummary(c, ctx, started, []*queryDocument{query}, reply, c.Writer.Status())
+	}
+}
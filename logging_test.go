@@ -0,0 +1,156 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type loggedRequest struct {
+	ctx   context.Context
+	entry RequestLog
+}
+
+type requestLogger struct {
+	requests []loggedRequest
+}
+
+func (l *requestLogger) LogRequest(ctx context.Context, entry RequestLog) {
+	l.requests = append(l.requests, loggedRequest{ctx, entry})
+}
+
+// Logger reading a value of the context of each request
+type contextReadingLogger struct {
+	values []interface{}
+}
+
+func (l *contextReadingLogger) LogRequest(ctx context.Context, entry RequestLog) {
+	l.values = append(l.values, ctx.Value("requestID"))
+}
+
+func TestLoggerRejectedByProviderPOST(t *testing.T) {
+	logger := &contextReadingLogger{}
+	app := New(schema, ContextProviderWithError(func(c *gin.Context, ctx context.Context) (context.Context, error) {
+		return nil, errors.New("rejected")
+	}))
+	app.Logger = logger
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if expected := `{"errors":[{"message":"request rejected (rejected)"}]}`; recorder.Body.String() != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+	if len(logger.values) != 1 || logger.values[0] != nil {
+		t.Errorf("Logged requests incorrect. Found %v", logger.values)
+	}
+}
+
+func TestLoggerPOST(t *testing.T) {
+	logger := &requestLogger{}
+	app := New(schema, func(c *gin.Context, ctx context.Context) context.Context {
+		return context.WithValue(ctx, "requestID", "r-1")
+	})
+	app.Logger = logger
+	router := setupRouter(app)
+
+	for _, body := range []string{
+		`{"query": "query greeting { hello }"}`,
+		`{"query": "mutation { unknown }"}`,
+		`{"query":`,
+	} {
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+		request.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	if len(logger.requests) != 3 {
+		t.Fatalf("Logged requests count incorrect. Found %d, expected %d", len(logger.requests), 3)
+	}
+	greeting := logger.requests[0]
+	if greeting.entry.Operation != "greeting" || greeting.entry.OperationType != "query" || len(greeting.entry.Errors) != 0 {
+		t.Errorf("Log entry incorrect. Found %+v", greeting.entry)
+	}
+	if greeting.entry.Duration <= 0 || greeting.entry.Upload {
+		t.Errorf("Log entry incorrect. Found %+v", greeting.entry)
+	}
+	if greeting.ctx.Value("requestID") != "r-1" {
+		t.Errorf("Log context lacks the resolver context values")
+	}
+	if mutation := logger.requests[1].entry; mutation.OperationType != "mutation" || len(mutation.Errors) != 1 {
+		t.Errorf("Log entry incorrect. Found %+v", mutation)
+	}
+	if malformed := logger.requests[2].entry; len(malformed.Errors) != 1 || malformed.Operation != "" {
+		t.Errorf("Log entry of rejected request incorrect. Found %+v", malformed)
+	}
+}
+
+func TestLoggerUploadPOST(t *testing.T) {
+	logger := &requestLogger{}
+	app := New(schema)
+	app.Logger = logger
+	router := setupRouter(app)
+
+	request := newUploadRequest(
+		`{"query": "mutation upload($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+		`{"file": ["variables.file"]}`,
+		map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+	)
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	if len(logger.requests) != 1 {
+		t.Fatalf("Logged requests count incorrect. Found %d, expected %d", len(logger.requests), 1)
+	}
+	if entry := logger.requests[0].entry; !entry.Upload || entry.Operation != "upload" || entry.OperationType != "mutation" {
+		t.Errorf("Log entry incorrect. Found %+v", entry)
+	}
+}
+
+type summaryLogger struct {
+	lines []string
+}
+
+func (l *summaryLogger) LogSummary(ctx context.Context, summary RequestSummary) {
+	l.lines = append(l.lines, summary.String())
+}
+
+func TestSummaryLoggerPOST(t *testing.T) {
+	logger := &summaryLogger{}
+	app := New(schema)
+	app.SummaryLogger = logger
+	router := setupRouter(app)
+
+	body := `{"query": "query greeting($secret: Boolean!) { hello @include(if: $secret) }", "variables": {"secret": true, "email": "jane@example.com"}}`
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+	request.Header.Add("Content-Type", "application/json")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	if len(logger.lines) != 1 {
+		t.Fatalf("Logged summaries count incorrect. Found %d, expected %d", len(logger.lines), 1)
+	}
+	line := logger.lines[0]
+	for _, expected := range []string{
+		`method=POST`,
+		`operation="greeting"`,
+		`errors=0`,
+		`status=200`,
+		`variables={email=[REDACTED] secret=[REDACTED]}`,
+	} {
+		if !strings.Contains(line, expected) {
+			t.Errorf("Summary line incorrect. Found %s, expected it to contain %s", line, expected)
+		}
+	}
+	if strings.Contains(line, "jane@example.com") || strings.Contains(line, "true") {
+		t.Errorf("Summary line leaks variable values. Found %s", line)
+	}
+}
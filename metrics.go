@@ -0,0 +1,21 @@
+package graphqlgin
+
+import (
+	"time"
+)
+
+// Receives the metrics of executed operations, i.e. to export them to Prometheus
+type MetricsRecorder interface {
+	// Called after each operation with its name, its type (query, mutation or
+	// subscription), whether the result has errors, and the execution duration.
+	RecordOperation(name string, operationType string, hasErrors bool, duration time.Duration)
+}
+
+// Returns the type of the operation requested by `query`, or an empty string if
+// the document is invalid.
+func requestOperationType(query *queryDocument) string {
+	if _, operation, err := query.Operation(); err == nil {
+		return operation.Operation
+	}
+	return ""
+}
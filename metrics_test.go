@@ -0,0 +1,54 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type recordedOperation struct {
+	name          string
+	operationType string
+	hasErrors     bool
+	duration      time.Duration
+}
+
+type operationRecorder struct {
+	operations []recordedOperation
+}
+
+func (r *operationRecorder) RecordOperation(name string, operationType string, hasErrors bool, duration time.Duration) {
+	r.operations = append(r.operations, recordedOperation{name, operationType, hasErrors, duration})
+}
+
+func TestMetricsRecorderPOST(t *testing.T) {
+	recorder := &operationRecorder{}
+	app := New(schema)
+	app.MetricsRecorder = recorder
+	router := setupRouter(app)
+
+	body := `[{"query": "query greeting { hello }"}, {"query": "mutation { unknown }"}, {"query": "query a { hello } query b { hello }", "operationName": "b"}]`
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	expected := []recordedOperation{
+		{"greeting", "query", false, 0},
+		{"", "mutation", true, 0},
+		{"b", "query", false, 0},
+	}
+	if len(recorder.operations) != len(expected) {
+		t.Fatalf("Recorded operations count incorrect. Found %d, expected %d", len(recorder.operations), len(expected))
+	}
+	for i, operation := range recorder.operations {
+		if operation.name != expected[i].name || operation.operationType != expected[i].operationType || operation.hasErrors != expected[i].hasErrors {
+			t.Errorf("Recorded operation incorrect. Found %+v, expected %+v", operation, expected[i])
+		}
+		if operation.duration <= 0 {
+			t.Errorf("Recorded duration incorrect. Found %s", operation.duration)
+		}
+	}
+}
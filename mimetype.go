@@ -0,0 +1,69 @@
+package graphqlgin
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// Returns the MIME type of an uploaded file, sniffed from its first 512 bytes
+// with `sniff`, or taken from the content type of its part otherwise.
+func uploadMIMEType(file *multipart.FileHeader, sniff bool) (string, error) {
+	if !sniff {
+		mediaType, _, err := mime.ParseMediaType(file.Header.Get("Content-Type"))
+		if err != nil {
+			return "application/octet-stream", nil
+		}
+		return mediaType, nil
+	}
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	head := make([]byte, 512)
+	n, err := io.ReadFull(src, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	return mediaType, nil
+}
+
+// Checks if `mediaType` matches one of `allowed`, which may contain wildcard
+// subtypes like `image/*`.
+func isAllowedMIMEType(mediaType string, allowed []string) bool {
+	for _, pattern := range allowed {
+		if strings.HasSuffix(pattern, "/*") {
+			if strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if strings.EqualFold(mediaType, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks the MIME type of an uploaded file against `AllowedUploadMIMETypes`.
+func (app *GraphQLApp) checkUploadType(file *multipart.FileHeader) *UploadError {
+	if len(app.AllowedUploadMIMETypes) == 0 {
+		return nil
+	}
+	mediaType, err := uploadMIMEType(file, app.SniffUploadMIMETypes)
+	if err != nil {
+		return uploadServerError("could not read file upload", err)
+	}
+	if !isAllowedMIMEType(mediaType, app.AllowedUploadMIMETypes) {
+		return &UploadError{
+			Category: UploadErrorClient,
+			Message:  "upload type not allowed",
+			Err:      fmt.Errorf("file %q of type %s is not allowed", file.Filename, mediaType),
+			Status:   http.StatusUnsupportedMediaType,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package graphqlgin
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAllowedUploadMIMETypesPOST(t *testing.T) {
+	app := New(schema)
+	app.AllowedUploadMIMETypes = []string{"image/*"}
+	app.SniffUploadMIMETypes = true
+	router := setupRouter(app)
+
+	for content, expected := range map[string]string{
+		"Hello, World":              `file \"upload.dat\" of type text/plain is not allowed`,
+		"\x89PNG\r\n\x1a\n\x00\x00": `{"data":{"singleUpload":{"size":10}}}`,
+	} {
+		recorder := httptest.NewRecorder()
+		request := newUploadRequest(
+			`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+			`{"file": ["variables.file"]}`,
+			map[string][2]string{"file": {"upload.dat", content}},
+		)
+
+		router.ServeHTTP(recorder, request)
+
+		if body := recorder.Body.String(); !strings.Contains(body, expected) {
+			t.Errorf("Response incorrect. Found %s, expected it to contain %s", body, expected)
+		}
+	}
+}
+
+func TestIsAllowedMIMEType(t *testing.T) {
+	allowed := []string{"image/*", "application/pdf"}
+	for mediaType, expected := range map[string]bool{
+		"image/png":       true,
+		"application/pdf": true,
+		"text/plain":      false,
+		"imagex/png":      false,
+	} {
+		if found := isAllowedMIMEType(mediaType, allowed); found != expected {
+			t.Errorf("Allowed %s incorrect. Found %v, expected %v", mediaType, found, expected)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package graphqlgin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Registers the GraphQL handler for GET and POST requests on `path` of `routes`.
+//
+// When `IgnoreTrailingSlash` is set, the handler is also registered on the
+// alternate form of `path` (with or without the trailing slash), so both are
+// served identically without relying on gin's redirect behavior.
+func (app *GraphQLApp) Mount(routes gin.IRoutes, path string, contextProviders ...ContextProviderFn) {
+	handler := app.Handler(contextProviders...)
+	paths := []string{path}
+	if app.IgnoreTrailingSlash {
+		if trimmed := strings.TrimRight(path, "/"); trimmed != path {
+			if trimmed != "" {
+				paths = append(paths, trimmed)
+			}
+		} else {
+			paths = append(paths, path+"/")
+		}
+	}
+	for _, p := range paths {
+		routes.GET(p, handler)
+		routes.POST(p, handler)
+	}
+}
+
+// Returns a `gin.HandlerFunc` to register with `router.NoRoute`, replying to
+// unmatched paths with a 404 in the GraphQL error format.
+func (app *GraphQLApp) NoRouteHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(
+			http.StatusNotFound,
+			graphqlErrorReply("not found", fmt.Errorf("no route for %s %s", c.Request.Method, c.Request.URL.Path)),
+		)
+	}
+}
+
+// Returns a `gin.HandlerFunc` to register with `router.NoMethod`, replying to
+// unsupported methods with a 405 in the GraphQL error format. Gin only calls it
+// when `HandleMethodNotAllowed` is enabled on the engine.
+func (app *GraphQLApp) NoMethodHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(
+			http.StatusMethodNotAllowed,
+			graphqlErrorReply("method not allowed", fmt.Errorf("method %s is not allowed on %s", c.Request.Method, c.Request.URL.Path)),
+		)
+	}
+}
@@ -0,0 +1,90 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMountTrailingSlash(t *testing.T) {
+	app := New(schema)
+	app.IgnoreTrailingSlash = true
+	router := gin.Default()
+	app.Mount(router, "/graphql")
+
+	var bodies []string
+	for _, path := range []string{"/graphql", "/graphql/"} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", path, bytes.NewBufferString(`{"query": "{ hello }"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Request to %s failed. Code: %d", path, recorder.Code)
+		}
+		bodies = append(bodies, recorder.Body.String())
+	}
+	if bodies[0] != bodies[1] || bodies[0] != `{"data":{"hello":"world"}}` {
+		t.Errorf("Responses differ. Found %s and %s", bodies[0], bodies[1])
+	}
+}
+
+func TestMountStrictSlash(t *testing.T) {
+	app := New(schema)
+	router := gin.Default()
+	router.RedirectTrailingSlash = false
+	app.Mount(router, "/graphql")
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/graphql/?query={hello}", nil)
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusNotFound)
+	}
+}
+
+func TestNoRouteHandler(t *testing.T) {
+	app := New(schema)
+	router := gin.Default()
+	app.Mount(router, "/graphql")
+	router.NoRoute(app.NoRouteHandler())
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/unknown", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusNotFound)
+	}
+	if body := recorder.Body.String(); body != `{"errors":[{"message":"not found (no route for POST /unknown)"}]}` {
+		t.Errorf("Response incorrect. Found %s", body)
+	}
+}
+
+func TestNoMethodHandler(t *testing.T) {
+	app := New(schema)
+	router := gin.Default()
+	router.HandleMethodNotAllowed = true
+	app.Mount(router, "/graphql")
+	router.NoMethod(app.NoMethodHandler())
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("DELETE", "/graphql", nil)
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusMethodNotAllowed)
+	}
+	if body := recorder.Body.String(); body != `{"errors":[{"message":"method not allowed (method DELETE is not allowed on /graphql)"}]}` {
+		t.Errorf("Response incorrect. Found %s", body)
+	}
+}
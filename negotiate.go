@@ -0,0 +1,64 @@
+package graphqlgin
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+// Response media type of the GraphQL over HTTP specification
+const MIMEGraphQLResponse = "application/graphql-response+json"
+
+// Checks if the `Accept` header accepts `mediaType` itself, not by a wildcard.
+func acceptsMediaType(accept string, mediaType string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		fields := strings.Split(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(fields[0]), mediaType) {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+		return quality > 0
+	}
+	return false
+}
+
+// Returns the content type of the replies to `c`, which is
+// `MIMEGraphQLResponse` when the client accepts it, and JSON otherwise.
+func responseContentType(c *gin.Context) string {
+	if acceptsMediaType(c.GetHeader("Accept"), MIMEGraphQLResponse) {
+		return MIMEGraphQLResponse + "; charset=utf-8"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// Returns 400 for replies to requests that failed as a whole (i.e. invalid
+// queries) when the client accepts `MIMEGraphQLResponse`, as its clients don't
+// expect these with a 200 status. Other statuses are returned as is.
+func (app *GraphQLApp) negotiatedStatus(c *gin.Context, status int, reply interface{}) int {
+	if status != http.StatusOK || app.StatusCodeFn != nil ||
+		!acceptsMediaType(c.GetHeader("Accept"), MIMEGraphQLResponse) {
+		return status
+	}
+	switch reply := reply.(type) {
+	case *graphql.Result:
+		if reply.Data == nil && len(reply.Errors) > 0 {
+			return http.StatusBadRequest
+		}
+	case map[string]interface{}:
+		if _, ok := reply["errors"]; ok {
+			return http.StatusBadRequest
+		}
+	}
+	return status
+}
@@ -0,0 +1,66 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponseContentTypePOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	for _, test := range []struct {
+		accept      string
+		query       string
+		code        int
+		contentType string
+	}{
+		{"", "{ hello }", http.StatusOK, "application/json; charset=utf-8"},
+		{"application/json", "{ unknown }", http.StatusOK, "application/json; charset=utf-8"},
+		{"*/*", "{ hello }", http.StatusOK, "application/json; charset=utf-8"},
+		{"application/graphql-response+json;q=0", "{ hello }", http.StatusOK, "application/json; charset=utf-8"},
+		{"application/graphql-response+json, application/json;q=0.9", "{ hello }", http.StatusOK, "application/graphql-response+json; charset=utf-8"},
+		{"application/graphql-response+json", "{ unknown }", http.StatusBadRequest, "application/graphql-response+json; charset=utf-8"},
+		{"application/graphql-response+json", "{ hello", http.StatusBadRequest, "application/graphql-response+json; charset=utf-8"},
+	} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "`+test.query+`"}`))
+		request.Header.Add("Content-Type", "application/json")
+		if test.accept != "" {
+			request.Header.Add("Accept", test.accept)
+		}
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != test.code {
+			t.Errorf("Status code with Accept %q incorrect. Found %d, expected %d", test.accept, recorder.Code, test.code)
+		}
+		if contentType := recorder.Header().Get("Content-Type"); contentType != test.contentType {
+			t.Errorf("Content type with Accept %q incorrect. Found %s, expected %s", test.accept, contentType, test.contentType)
+		}
+	}
+}
+
+func TestResponseContentTypeEncoderPOST(t *testing.T) {
+	app := New(schema)
+	app.ResponseEncoder = func(w io.Writer, v interface{}) error {
+		return json.NewEncoder(w).Encode(v)
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("Accept", MIMEGraphQLResponse)
+
+	router.ServeHTTP(recorder, request)
+
+	expected := MIMEGraphQLResponse + "; charset=utf-8"
+	if contentType := recorder.Header().Get("Content-Type"); contentType != expected {
+		t.Errorf("Content type incorrect. Found %s, expected %s", contentType, expected)
+	}
+}
@@ -0,0 +1,51 @@
+package graphqlgin
+
+import (
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Function to configure a `GraphQLApp` created by `NewWithOptions`
+type Option func(app *GraphQLApp)
+
+// Adds context providers to the app.
+func WithContextProvider(contextProviders ...ContextProviderFn) Option {
+	return func(app *GraphQLApp) {
+		app.ContextProviders = append(app.ContextProviders, contextProviders...)
+	}
+}
+
+// Sets `MaxUploadSize` of the app.
+func WithMaxUploadSize(size int64) Option {
+	return func(app *GraphQLApp) {
+		app.MaxUploadSize = size
+	}
+}
+
+// Sets `RequestTimeout` of the app.
+func WithRequestTimeout(timeout time.Duration) Option {
+	return func(app *GraphQLApp) {
+		app.RequestTimeout = timeout
+	}
+}
+
+// Sets `ErrorFormatter` of the app.
+func WithErrorFormatter(formatter ErrorFormatterFn) Option {
+	return func(app *GraphQLApp) {
+		app.ErrorFormatter = formatter
+	}
+}
+
+// Constructs a new GraphQL app configured by `opts`, which are applied in order.
+func NewWithOptions(schema graphql.Schema, opts ...Option) *GraphQLApp {
+	schema.AppendType(UploadType)
+	app := &GraphQLApp{
+		Schema:           schema,
+		ContextProviders: []ContextProviderFn{GinContextProvider},
+	}
+	for _, opt := range opts {
+		opt(app)
+	}
+	return app
+}
@@ -0,0 +1,52 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	formatter := func(ctx context.Context, errs []gqlerrors.FormattedError) []gqlerrors.FormattedError {
+		return errs
+	}
+	app := NewWithOptions(
+		schema,
+		WithContextProvider(prefixProvider),
+		WithMaxUploadSize(1024),
+		WithRequestTimeout(time.Second),
+		WithErrorFormatter(formatter),
+	)
+
+	if len(app.ContextProviders) != 2 {
+		t.Errorf("Context providers count incorrect. Found %d, expected %d", len(app.ContextProviders), 2)
+	}
+	if app.MaxUploadSize != 1024 {
+		t.Errorf("MaxUploadSize incorrect. Found %d, expected %d", app.MaxUploadSize, 1024)
+	}
+	if app.RequestTimeout != time.Second {
+		t.Errorf("RequestTimeout incorrect. Found %s, expected %s", app.RequestTimeout, time.Second)
+	}
+	if app.ErrorFormatter == nil {
+		t.Errorf("ErrorFormatter not set")
+	}
+
+	router := gin.New()
+	router.POST("/", app.Handler())
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"hello":"world"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
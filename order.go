@@ -0,0 +1,66 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Response object serialized with its keys in the order they were selected
+type orderedObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	buff := bytes.NewBufferString("{")
+	for i, key := range o.keys {
+		if i > 0 {
+			buff.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buff.Write(name)
+		buff.WriteByte(':')
+		buff.Write(value)
+	}
+	buff.WriteByte('}')
+	return buff.Bytes(), nil
+}
+
+// Orders the objects of a response `value` by the selection order of `fields`.
+// Fields selected more than once, i.e. through fragments, keep their first position.
+func orderValue(value interface{}, fields []*selectedField) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		ordered := &orderedObject{values: map[string]interface{}{}}
+		children := map[string][]*selectedField{}
+		for _, field := range fields {
+			key := field.Path[len(field.Path)-1]
+			if _, ok := value[key]; !ok {
+				// i.e. a field of a fragment on another type
+				continue
+			}
+			if _, ok := children[key]; !ok {
+				ordered.keys = append(ordered.keys, key)
+			}
+			children[key] = append(children[key], field.Children...)
+		}
+		for _, key := range ordered.keys {
+			ordered.values[key] = orderValue(value[key], children[key])
+		}
+		return ordered
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, item := range value {
+			list[i] = orderValue(item, fields)
+		}
+		return list
+	}
+	return value
+}
@@ -0,0 +1,61 @@
+package graphqlgin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Function called with the recovered value and stack trace of a panic during
+// the execution of an operation, i.e. to log it
+type PanicHandlerFn func(c *gin.Context, recovered interface{}, stack []byte)
+
+// Error replied for operations which panicked, not revealing the panic value
+var errExecutionPanicked = errors.New("the operation could not be completed")
+
+// Key for keeping the values of the panics recovered during the current request
+// in the `*gin.Context`, for the request log
+const recoveredPanicsKey = "graphqlgin.panics"
+
+// Recovers a panic during the execution of an operation, reporting it to the
+// `PanicHandler` and replacing the reply with a generic internal error. The
+// `Logger` gets the recovered value in place of the generic message.
+func (app *GraphQLApp) recoverExecution(c *gin.Context, status *int, reply *interface{}) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if app.PanicHandler != nil {
+		app.PanicHandler(c, recovered, debug.Stack())
+	}
+	recordPanic(c, fmt.Sprint(recovered))
+	*status = http.StatusOK
+	*reply = graphqlErrorReply("internal error", errExecutionPanicked)
+}
+
+// Keeps the recovered panic `message` of the current request for the request
+// log.
+func recordPanic(c *gin.Context, message string) {
+	panics := c.GetStringSlice(recoveredPanicsKey)
+	c.Set(recoveredPanicsKey, append(panics, message))
+}
+
+// Restores the recovered panic values of the request in the messages of the
+// generic internal errors of `errs`, in order.
+func restorePanicMessages(c *gin.Context, errs []gqlerrors.FormattedError) {
+	panics := c.GetStringSlice(recoveredPanicsKey)
+	generic := fmt.Sprintf("internal error (%s)", errExecutionPanicked)
+	for i := range errs {
+		if len(panics) == 0 {
+			return
+		}
+		if errs[i].Message == generic {
+			errs[i].Message = fmt.Sprintf("internal error (%s)", panics[0])
+			panics = panics[1:]
+		}
+	}
+}
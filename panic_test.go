@@ -0,0 +1,107 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+var panicScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name: "Panic",
+	Serialize: func(value interface{}) interface{} {
+		panic("cannot serialize")
+	},
+})
+
+var panicScalarSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"broken": &graphql.Field{
+				Type: panicScalar,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return "value", nil
+				},
+			},
+		},
+	}),
+})
+
+func TestPanicHandlerPOST(t *testing.T) {
+	var recovered interface{}
+	var stack []byte
+	app := New(panicScalarSchema)
+	app.ParamsMutator = func(c *gin.Context, params *graphql.Params) {
+		panic("mutator failed")
+	}
+	app.PanicHandler = func(c *gin.Context, r interface{}, s []byte) {
+		recovered, stack = r, s
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ broken }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusOK)
+	}
+	if body := recorder.Body.String(); body != `{"errors":[{"message":"internal error (the operation could not be completed)"}]}` {
+		t.Errorf("Response incorrect. Found %s", body)
+	}
+	if recovered != "mutator failed" || len(stack) == 0 {
+		t.Errorf("Panic not reported. Found %v", recovered)
+	}
+}
+
+func TestScalarPanicPOST(t *testing.T) {
+	app := New(panicScalarSchema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ broken }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusOK)
+	}
+	if body := recorder.Body.String(); !strings.Contains(body, `"errors"`) {
+		t.Errorf("Error not found. Body: %s", body)
+	}
+}
+
+func TestPanicLoggedPOST(t *testing.T) {
+	logger := &requestLogger{}
+	app := New(schema)
+	app.ParamsMutator = func(c *gin.Context, params *graphql.Params) {
+		panic("secret details")
+	}
+	app.Logger = logger
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if body := recorder.Body.String(); strings.Contains(body, "secret details") {
+		t.Errorf("Panic value sent to the client. Body: %s", body)
+	}
+	if len(logger.requests) != 1 {
+		t.Fatalf("Logged requests count incorrect. Found %d, expected %d", len(logger.requests), 1)
+	}
+	errs := logger.requests[0].entry.Errors
+	if len(errs) != 1 || errs[0].Message != "internal error (secret details)" {
+		t.Errorf("Logged errors incorrect. Found %+v", errs)
+	}
+}
@@ -0,0 +1,27 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed assets/playground.html
+var playgroundAssets embed.FS
+
+var playgroundTemplate = template.Must(template.ParseFS(playgroundAssets, "assets/playground.html"))
+
+// Returns a `gin.HandlerFunc` serving an in-browser GraphQL IDE which sends its
+// queries, along with any custom headers entered, to `endpoint`. All assets are
+// embedded, so it works without network access. Meant for development, i.e.
+// `router.GET("/", app.PlaygroundHandler("/graphql"))`.
+func (app *GraphQLApp) PlaygroundHandler(endpoint string) gin.HandlerFunc {
+	page := bytes.NewBuffer(nil)
+	playgroundTemplate.Execute(page, map[string]string{"Endpoint": endpoint})
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page.Bytes())
+	}
+}
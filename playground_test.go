@@ -0,0 +1,38 @@
+package graphqlgin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestPlaygroundHandler(t *testing.T) {
+	app := New(schema)
+	router := gin.Default()
+	router.GET("/", app.PlaygroundHandler("/graphql?tenant=a&b"))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/", nil)
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusOK)
+	}
+	if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/html") {
+		t.Errorf("Content type incorrect. Found %s, expected text/html", contentType)
+	}
+	body := recorder.Body.String()
+	if !strings.Contains(body, `<code id="endpoint">/graphql?tenant=a&amp;b</code>`) {
+		t.Errorf("Endpoint not found in the page")
+	}
+	if !strings.Contains(body, `id="headers"`) {
+		t.Errorf("Headers input not found in the page")
+	}
+	if strings.Contains(body, "<script src=") || strings.Contains(body, "<link") {
+		t.Errorf("Page loads external assets")
+	}
+}
@@ -0,0 +1,194 @@
+package graphqlgin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error returned when a context provider exceeds `ContextProviderTimeout`
+var ErrContextProviderTimeout = errors.New("context provider timed out")
+
+// Function to update the context passed down to the resolver functions, which
+// may reject the request by returning an error
+type ContextProviderWithErrorFn func(c *gin.Context, ctx context.Context) (context.Context, error)
+
+// Error of a context provider rejecting the request
+type ContextProviderError struct {
+	Err error
+}
+
+func (e *ContextProviderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ContextProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Key for passing the error of a `ContextProviderWithErrorFn` to `provideContext`
+const providerErrorKey contextKey = "ContextProviderError"
+
+// Adapts `provider` to a `ContextProviderFn`, so it can be used wherever context
+// providers are accepted. When `provider` returns an error, the remaining
+// providers are skipped and the request is rejected with the error.
+func ContextProviderWithError(provider ContextProviderWithErrorFn) ContextProviderFn {
+	return func(c *gin.Context, ctx context.Context) context.Context {
+		providedCtx, err := provider(c, ctx)
+		if err != nil {
+			return context.WithValue(ctx, providerErrorKey, &ContextProviderError{Err: err})
+		}
+		return providedCtx
+	}
+}
+
+// Appends `providers` to the context providers of the app, returning the app
+// for chaining. Handlers created before keep the providers they were created with.
+func (app *GraphQLApp) Use(providers ...ContextProviderFn) *GraphQLApp {
+	app.ContextProviders = append(app.ContextProviders, providers...)
+	return app
+}
+
+// Context provider registered with `SetContextProvider`
+type namedContextProvider struct {
+	name     string
+	provider ContextProviderFn
+}
+
+// Registers `provider` under `name`, replacing the provider of the same name
+// in place. Named providers run in insertion order after `ContextProviders`.
+func (app *GraphQLApp) SetContextProvider(name string, provider ContextProviderFn) *GraphQLApp {
+	for i := range app.namedProviders {
+		if app.namedProviders[i].name == name {
+			app.namedProviders[i].provider = provider
+			return app
+		}
+	}
+	app.namedProviders = append(app.namedProviders, namedContextProvider{name, provider})
+	return app
+}
+
+// Removes the provider registered under `name`, if any.
+func (app *GraphQLApp) RemoveContextProvider(name string) *GraphQLApp {
+	for i := range app.namedProviders {
+		if app.namedProviders[i].name == name {
+			app.namedProviders = append(app.namedProviders[:i:i], app.namedProviders[i+1:]...)
+			break
+		}
+	}
+	return app
+}
+
+// Removes all context providers, named or not, except `GinContextProvider`.
+func (app *GraphQLApp) ClearContextProviders() *GraphQLApp {
+	app.ContextProviders = []ContextProviderFn{GinContextProvider}
+	app.namedProviders = nil
+	return app
+}
+
+// Returns the providers of a handler: `ContextProviders`, the named providers,
+// then `contextProviders` passed to the handler factory. Changes to the app
+// providers don't affect the handlers created before.
+func (app *GraphQLApp) handlerProviders(contextProviders []ContextProviderFn) []ContextProviderFn {
+	providers := make([]ContextProviderFn, 0, len(app.ContextProviders)+len(app.namedProviders)+len(contextProviders))
+	providers = append(providers, app.ContextProviders...)
+	for _, named := range app.namedProviders {
+		providers = append(providers, named.provider)
+	}
+	return append(providers, contextProviders...)
+}
+
+// Returns the response status code for requests rejected by a context provider.
+func (app *GraphQLApp) contextProviderErrorStatus() int {
+	if app.ContextProviderErrorStatus != 0 {
+		return app.ContextProviderErrorStatus
+	}
+	return http.StatusOK
+}
+
+// Context carrying the values of the context returned by a provider run with
+// a timeout, but the cancellation of the request context, since the deadline
+// of the provider is cancelled once it returns.
+type providedContext struct {
+	context.Context
+	provided context.Context
+}
+
+func (ctx *providedContext) Value(key interface{}) interface{} {
+	return ctx.provided.Value(key)
+}
+
+// Runs `provider` with the `timeout` bound. The provider gets a context
+// cancelled once the timeout expires, so it can stop early, and the request is
+// rejected if the provider returns after it. A panicking provider rejects the
+// request with an error.
+func runProviderWithTimeout(provider ContextProviderFn, c *gin.Context, ctx context.Context, timeout time.Duration) (provided context.Context, err error) {
+	providerCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			provided, err = nil, fmt.Errorf("context provider panicked: %v", r)
+		}
+	}()
+
+	result := provider(c, providerCtx)
+	if providerCtx.Err() != nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w after %s", ErrContextProviderTimeout, timeout)
+	}
+	return &providedContext{Context: ctx, provided: result}, nil
+}
+
+// Creates the resolver context by running each of the `providers` sequentially.
+func (app *GraphQLApp) provideContext(c *gin.Context, ctx context.Context, providers []ContextProviderFn) (context.Context, error) {
+	for _, provider := range providers {
+		if app.ContextProviderTimeout <= 0 {
+			ctx = provider(c, ctx)
+		} else {
+			providedCtx, err := runProviderWithTimeout(provider, c, ctx, app.ContextProviderTimeout)
+			if err != nil {
+				return nil, err
+			}
+			ctx = providedCtx
+		}
+		// stop at the first provider rejecting the request
+		if err, ok := ctx.Value(providerErrorKey).(*ContextProviderError); ok {
+			return nil, err
+		}
+	}
+	return ctx, nil
+}
+
+// Runs the context providers in the background while parsing and validating
+// `queries`, returning once both are done. A panicking provider rejects the
+// request with an error.
+func (app *GraphQLApp) provideContextWhileValidating(c *gin.Context, ctx context.Context, providers []ContextProviderFn, queries []*queryDocument) (context.Context, error) {
+	type provided struct {
+		ctx context.Context
+		err error
+	}
+	done := make(chan provided, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- provided{err: fmt.Errorf("context provider panicked: %v", r)}
+			}
+		}()
+		providedCtx, err := app.provideContext(c, ctx, providers)
+		done <- provided{providedCtx, err}
+	}()
+	for _, query := range queries {
+		// the query of trusted and persisted queries is known after loading it
+		if app.loadQuery(query) == nil {
+			query.Validate(&app.Schema)
+		}
+	}
+	result := <-done
+	return result.ctx, result.err
+}
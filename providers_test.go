@@ -0,0 +1,335 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+func TestContextProviderTimeoutPOST(t *testing.T) {
+	app := New(schema, func(c *gin.Context, ctx context.Context) context.Context {
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-ctx.Done():
+		}
+		return ctx
+	})
+	app.ContextProviderTimeout = 10 * time.Millisecond
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	start := time.Now()
+	router.ServeHTTP(recorder, request)
+
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Request was not aborted in time. Took %s", elapsed)
+	}
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+	if !strings.Contains(recorder.Body.String(), ErrContextProviderTimeout.Error()) {
+		t.Errorf("Timeout error not found. Body: %s", recorder.Body.String())
+	}
+}
+
+func TestContextProviderWithinTimeoutPOST(t *testing.T) {
+	app := New(schema, func(c *gin.Context, ctx context.Context) context.Context {
+		return context.WithValue(ctx, "value", 5)
+	})
+	app.ContextProviderTimeout = time.Second
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ context }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Body.String() != `{"data":{"context":5}}` {
+		t.Errorf("Response incorrect. Found %s", recorder.Body.String())
+	}
+}
+
+func TestContextProviderTimeoutContextPOST(t *testing.T) {
+	var providerCtx context.Context
+	var providerGinContext, resolverGinContext, ginContext *gin.Context
+	app := New(schema, func(c *gin.Context, ctx context.Context) context.Context {
+		providerCtx, providerGinContext = ctx, c
+		return context.WithValue(ctx, "value", 5)
+	})
+	app.ContextProviderTimeout = time.Second
+	app.ParamsMutator = func(c *gin.Context, params *graphql.Params) {
+		ginContext, resolverGinContext = c, GetGinContext(params.Context)
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ context }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Body.String() != `{"data":{"context":5}}` {
+		t.Errorf("Response incorrect. Found %s", recorder.Body.String())
+	}
+	if _, ok := providerCtx.Deadline(); !ok {
+		t.Errorf("Provider context has no deadline")
+	}
+	if providerCtx.Err() != context.Canceled {
+		t.Errorf("Provider context not cancelled. Found %v, expected %v", providerCtx.Err(), context.Canceled)
+	}
+	if providerGinContext != ginContext {
+		t.Errorf("Provider did not get the request gin context")
+	}
+	if resolverGinContext != ginContext {
+		t.Errorf("Resolvers did not get the request gin context")
+	}
+}
+
+func TestTraceParentProviderWithTimeoutPOST(t *testing.T) {
+	app := New(schema, TraceParentProvider)
+	app.ContextProviderTimeout = time.Second
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Body.String() != `{"data":{"hello":"world"}}` {
+		t.Errorf("Response incorrect. Found %s", recorder.Body.String())
+	}
+	if traceParent := recorder.Header().Get(TraceParentHeader); !validTraceParent(traceParent) {
+		t.Errorf("Trace parent header incorrect. Found %s", traceParent)
+	}
+}
+
+func TestContextProviderPanicWithTimeoutPOST(t *testing.T) {
+	app := New(schema, func(c *gin.Context, ctx context.Context) context.Context {
+		panic("provider failed")
+	})
+	app.ContextProviderTimeout = time.Second
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+	expected := `{"errors":[{"message":"could not create resolver context (context provider panicked: provider failed)"}]}`
+	if recorder.Body.String() != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+}
+
+func TestConcurrentContextProviderPanicPOST(t *testing.T) {
+	app := New(schema, func(c *gin.Context, ctx context.Context) context.Context {
+		panic("provider failed")
+	})
+	app.ConcurrentContextProviders = true
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+	expected := `{"errors":[{"message":"could not create resolver context (context provider panicked: provider failed)"}]}`
+	if recorder.Body.String() != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+}
+
+func TestConcurrentContextProvidersPOST(t *testing.T) {
+	// the provider and the validation each wait for the other to start, which
+	// only completes when they run at the same time
+	providing := make(chan struct{})
+	validating := make(chan struct{})
+	var validation sync.Once
+	probeType := graphql.NewScalar(graphql.ScalarConfig{
+		Name: "Probe",
+		Serialize: func(value interface{}) interface{} {
+			return value
+		},
+		ParseValue: func(value interface{}) interface{} {
+			return value
+		},
+		ParseLiteral: func(valueAST ast.Value) interface{} {
+			// first called by the validation of the argument
+			validation.Do(func() {
+				close(validating)
+				select {
+				case <-providing:
+				case <-time.After(time.Second):
+				}
+			})
+			return valueAST.GetValue()
+		},
+	})
+	probeSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"probe": &graphql.Field{
+					Type: probeType,
+					Args: graphql.FieldConfigArgument{
+						"value": &graphql.ArgumentConfig{Type: probeType},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return p.Args["value"], nil
+					},
+				},
+			},
+		}),
+	})
+
+	overlapped := false
+	app := New(probeSchema, func(c *gin.Context, ctx context.Context) context.Context {
+		close(providing)
+		select {
+		case <-validating:
+			overlapped = true
+		case <-time.After(time.Second):
+		}
+		return ctx
+	})
+	app.ConcurrentContextProviders = true
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ probe(value: \"x\") }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Body.String() != `{"data":{"probe":"x"}}` {
+		t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), `{"data":{"probe":"x"}}`)
+	}
+	if !overlapped {
+		t.Errorf("Context provider did not run while validating the query")
+	}
+}
+
+func authProvider(c *gin.Context, ctx context.Context) (context.Context, error) {
+	if c.GetHeader("Authorization") != "Bearer token" {
+		return nil, errors.New("invalid token")
+	}
+	return context.WithValue(ctx, "value", 7), nil
+}
+
+func TestContextProviderWithErrorPOST(t *testing.T) {
+	cases := []struct {
+		authorization string
+		errorStatus   int
+		status        int
+		body          string
+	}{
+		{"Bearer token", 0, http.StatusOK, `{"data":{"context":7}}`},
+		{"", 0, http.StatusOK, `{"errors":[{"message":"request rejected (invalid token)"}]}`},
+		{"", http.StatusUnauthorized, http.StatusUnauthorized, `{"errors":[{"message":"request rejected (invalid token)"}]}`},
+	}
+	for _, tc := range cases {
+		app := New(schema, ContextProviderWithError(authProvider), func(c *gin.Context, ctx context.Context) context.Context {
+			if ctx.Value("value") == nil {
+				t.Errorf("Provider called after the request was rejected")
+			}
+			return ctx
+		})
+		app.ContextProviderErrorStatus = tc.errorStatus
+		router := setupRouter(app)
+
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ context }"}`))
+		request.Header.Add("Content-Type", "application/json")
+		request.Header.Add("Authorization", tc.authorization)
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != tc.status {
+			t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, tc.status)
+		}
+		if body := recorder.Body.String(); body != tc.body {
+			t.Errorf("Response incorrect. Found %s, expected %s", body, tc.body)
+		}
+	}
+}
+
+func TestUseContextProvidersPOST(t *testing.T) {
+	app := New(schema).
+		Use(func(c *gin.Context, ctx context.Context) context.Context {
+			return context.WithValue(ctx, "value", 2)
+		}).
+		Use(func(c *gin.Context, ctx context.Context) context.Context {
+			return context.WithValue(ctx, "value", ctx.Value("value").(int)*3)
+		})
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ context }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if expected := `{"data":{"context":6}}`; recorder.Body.String() != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+	if len(app.ContextProviders) != 3 {
+		t.Errorf("Context providers count incorrect. Found %d, expected %d", len(app.ContextProviders), 3)
+	}
+}
+
+func TestNamedContextProvidersPOST(t *testing.T) {
+	valueProvider := func(value int) ContextProviderFn {
+		return func(c *gin.Context, ctx context.Context) context.Context {
+			return context.WithValue(ctx, "value", value)
+		}
+	}
+	app := New(schema).
+		SetContextProvider("db", valueProvider(1)).
+		SetContextProvider("mock", valueProvider(2)).
+		SetContextProvider("db", valueProvider(3))
+
+	query := func() string {
+		router := setupRouter(app)
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ context }"}`))
+		request.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(recorder, request)
+		return recorder.Body.String()
+	}
+
+	// replaced providers keep their position, so mock runs last
+	if expected := `{"data":{"context":2}}`; query() != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", query(), expected)
+	}
+	app.RemoveContextProvider("mock")
+	if expected := `{"data":{"context":3}}`; query() != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", query(), expected)
+	}
+	app.ClearContextProviders()
+	if count := app.Config().ContextProviders; count != 1 {
+		t.Errorf("Context providers count incorrect. Found %d, expected %d", count, 1)
+	}
+}
@@ -0,0 +1,100 @@
+package graphqlgin
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// In memory LRU cache of parsed documents which passed validation, keyed by
+// the hash of their normalized request string
+type documentCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type cachedDocument struct {
+	query    string
+	document *ast.Document
+}
+
+func newDocumentCache(size int) *documentCache {
+	return &documentCache{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+func (cache *documentCache) Get(query string) (*ast.Document, bool) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	element, ok := cache.entries[query]
+	if !ok {
+		return nil, false
+	}
+	cache.order.MoveToFront(element)
+	return element.Value.(*cachedDocument).document, true
+}
+
+func (cache *documentCache) Set(query string, document *ast.Document) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if element, ok := cache.entries[query]; ok {
+		element.Value.(*cachedDocument).document = document
+		cache.order.MoveToFront(element)
+		return
+	}
+	cache.entries[query] = cache.order.PushFront(&cachedDocument{query: query, document: document})
+	for cache.order.Len() > cache.size {
+		oldest := cache.order.Back()
+		cache.order.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*cachedDocument).query)
+	}
+}
+
+// Returns the document cache of the app, creating it on first use.
+func (app *GraphQLApp) documentCache() *documentCache {
+	app.queryCacheOnce.Do(func() {
+		app.queryCache = newDocumentCache(app.QueryCacheSize)
+	})
+	return app.queryCache
+}
+
+// Returns the document cache key of the request string `query`, shared by
+// the queries differing only in whitespace, commas or comments.
+func documentCacheKey(query string) string {
+	key, _ := HashRequest(GraphQLRequestParams{RequestString: query})
+	return key
+}
+
+// Sets the parsed and validated document of `query` from the cache, or parses
+// and validates it, caching it if valid. Equivalent queries formatted
+// differently are parsed again, so error locations match their request
+// string, but not validated.
+func (app *GraphQLApp) loadCachedDocument(query *queryDocument) {
+	cache := app.documentCache()
+	requestString := query.params.RequestString
+	key := documentCacheKey(requestString)
+	if !query.Valid() {
+		if document, ok := cache.Get(key); ok {
+			if document.Loc != nil && document.Loc.Source != nil && string(document.Loc.Source.Body) == requestString {
+				query.document, query.err = document, nil
+				query.source, query.fields = requestString, nil
+				query.parsed, query.valid = true, true
+				return
+			}
+			if _, err := query.Document(); err == nil {
+				query.valid = true
+				return
+			}
+		}
+		if !query.Validate(&app.Schema) {
+			return
+		}
+	}
+	cache.Set(key, query.document)
+}
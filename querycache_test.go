@@ -0,0 +1,102 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQueryCachePOST(t *testing.T) {
+	app := New(schema)
+	app.QueryCacheSize = 1
+	router := setupRouter(app)
+
+	for _, test := range []struct {
+		query    string
+		expected string
+	}{
+		{`{ hello }`, `{"data":{"hello":"world"}}`},
+		{`{ hello }`, `{"data":{"hello":"world"}}`},
+		{`{ unknown }`, `{"data":null,"errors":[{"message":"Cannot query field \"unknown\" on type \"Query\".","locations":[{"line":1,"column":3}]}]}`},
+	} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "`+test.query+`"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if body := recorder.Body.String(); body != test.expected {
+			t.Errorf("Response incorrect. Found %s, expected %s", body, test.expected)
+		}
+	}
+
+	// invalid queries are not cached
+	cache := app.documentCache()
+	if _, ok := cache.Get(documentCacheKey(`{ unknown }`)); ok {
+		t.Errorf("Invalid query was cached")
+	}
+	if _, ok := cache.Get(documentCacheKey(`{ hello }`)); !ok {
+		t.Errorf("Valid query was not cached")
+	}
+}
+
+func TestQueryCacheFormattingPOST(t *testing.T) {
+	app := New(schema)
+	app.QueryCacheSize = 2
+	router := setupRouter(app)
+
+	for _, query := range []string{`{ hello }`, `{ hello, }`, `# greeting\n{\n  hello\n}`} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "`+query+`"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if body := recorder.Body.String(); body != `{"data":{"hello":"world"}}` {
+			t.Errorf("Response incorrect. Found %s, expected %s", body, `{"data":{"hello":"world"}}`)
+		}
+	}
+
+	// queries differing only in insignificant tokens share an entry
+	if entries := app.documentCache().order.Len(); entries != 1 {
+		t.Errorf("Cached documents count incorrect. Found %d, expected %d", entries, 1)
+	}
+}
+
+func TestDocumentCacheEviction(t *testing.T) {
+	cache := newDocumentCache(2)
+	for _, query := range []string{"a", "b", "a", "c"} {
+		cache.Set(query, nil)
+	}
+	if _, ok := cache.Get("b"); ok {
+		t.Errorf("Least recently used query was not evicted")
+	}
+	for _, query := range []string{"a", "c"} {
+		if _, ok := cache.Get(query); !ok {
+			t.Errorf("Query %s was evicted", query)
+		}
+	}
+}
+
+func benchmarkHotQuery(b *testing.B, cacheSize int) {
+	app := New(schema)
+	app.QueryCacheSize = cacheSize
+	router := setupRouter(app)
+	body := []byte(`{"query": "query greeting { a: hello b: hello c: hello ... on Query { d: hello } }"}`)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		request, _ := http.NewRequest("POST", "/", bytes.NewBuffer(body))
+		request.Header.Add("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+}
+
+func BenchmarkHotQuery(b *testing.B) {
+	benchmarkHotQuery(b, 0)
+}
+
+func BenchmarkHotQueryCached(b *testing.B) {
+	benchmarkHotQuery(b, 100)
+}
@@ -0,0 +1,87 @@
+package graphqlgin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits the operations clients may execute
+type RateLimiter interface {
+	// Returns false if the operation must be rejected. `ctx` is the resolver
+	// context, carrying the operation name for `GetOperationName`.
+	Allow(ctx context.Context, c *gin.Context) bool
+}
+
+// Key for passing the name of the operation to the `RateLimiter`
+const operationNameKey contextKey = "OperationName"
+
+// Returns the name of the operation being rate limited, which is empty for
+// anonymous operations.
+func GetOperationName(ctx context.Context) string {
+	name, _ := ctx.Value(operationNameKey).(string)
+	return name
+}
+
+// Number of clients tracked by `NewIPRateLimiter` before full buckets are pruned
+const rateLimiterPruneSize = 10000
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Token bucket rate limiter keyed by client IP
+type ipRateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+// Returns a `RateLimiter` allowing each client IP `rate` operations per second
+// on average, with bursts of up to `burst` operations.
+func NewIPRateLimiter(rate float64, burst int) RateLimiter {
+	return &ipRateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: map[string]*tokenBucket{},
+		now:     time.Now,
+	}
+}
+
+func (l *ipRateLimiter) Allow(ctx context.Context, c *gin.Context) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if len(l.buckets) >= rateLimiterPruneSize {
+		l.prune(now)
+	}
+	bucket, ok := l.buckets[c.ClientIP()]
+	if !ok {
+		bucket = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[c.ClientIP()] = bucket
+	}
+	bucket.tokens += now.Sub(bucket.last).Seconds() * l.rate
+	if bucket.tokens > l.burst {
+		bucket.tokens = l.burst
+	}
+	bucket.last = now
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// Removes the buckets which have refilled, as they behave like new ones.
+func (l *ipRateLimiter) prune(now time.Time) {
+	for ip, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, ip)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type operationRateLimiter struct {
+	denied string
+}
+
+func (l operationRateLimiter) Allow(ctx context.Context, c *gin.Context) bool {
+	return GetOperationName(ctx) != l.denied
+}
+
+func rateLimitedRequest(router *gin.Engine, remoteAddr string, query string) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "`+query+`"}`))
+	request.Header.Add("Content-Type", "application/json")
+	request.RemoteAddr = remoteAddr
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestIPRateLimiterPOST(t *testing.T) {
+	app := New(schema)
+	app.RateLimiter = NewIPRateLimiter(0.001, 2)
+	router := setupRouter(app)
+
+	for i, expected := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		if recorder := rateLimitedRequest(router, "10.0.0.1:1234", "{ hello }"); recorder.Code != expected {
+			t.Errorf("Status code of request %d incorrect. Found %d, expected %d", i, recorder.Code, expected)
+		}
+	}
+	if recorder := rateLimitedRequest(router, "10.0.0.2:1234", "{ hello }"); recorder.Code != http.StatusOK {
+		t.Errorf("Status code of other client incorrect. Found %d, expected %d", recorder.Code, http.StatusOK)
+	}
+}
+
+func TestIPRateLimiterRefill(t *testing.T) {
+	now := time.Now()
+	limiter := NewIPRateLimiter(1, 1).(*ipRateLimiter)
+	limiter.now = func() time.Time { return now }
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/", nil)
+
+	for i, expected := range []bool{true, false} {
+		if allowed := limiter.Allow(context.Background(), c); allowed != expected {
+			t.Errorf("Allowed %d incorrect. Found %v, expected %v", i, allowed, expected)
+		}
+	}
+	now = now.Add(time.Second)
+	if !limiter.Allow(context.Background(), c) {
+		t.Errorf("Bucket was not refilled")
+	}
+}
+
+func TestOperationRateLimiterPOST(t *testing.T) {
+	app := New(schema)
+	app.RateLimiter = operationRateLimiter{denied: "expensive"}
+	router := setupRouter(app)
+
+	if recorder := rateLimitedRequest(router, "10.0.0.1:1234", "query expensive { hello }"); recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusTooManyRequests)
+	}
+	if recorder := rateLimitedRequest(router, "10.0.0.1:1234", "query cheap { hello }"); recorder.Body.String() != `{"data":{"hello":"world"}}` {
+		t.Errorf("Response incorrect. Found %s", recorder.Body.String())
+	}
+}
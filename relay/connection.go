@@ -0,0 +1,220 @@
+package relay
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Prefix of the offset encoded in cursors made by `OffsetToCursor`
+const cursorPrefix = "offset:"
+
+// Pagination info of a connection
+type PageInfo struct {
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	HasNextPage     bool    `json:"hasNextPage"`
+}
+
+// Node of a connection with its cursor
+type Edge struct {
+	Node   interface{} `json:"node"`
+	Cursor string      `json:"cursor"`
+}
+
+// Page of a list returned by a connection field
+type Connection struct {
+	Edges    []*Edge  `json:"edges"`
+	PageInfo PageInfo `json:"pageInfo"`
+}
+
+// Pagination arguments of a connection field
+type ConnectionArguments struct {
+	First  int
+	After  string
+	Last   int
+	Before string
+}
+
+// Arguments of a connection field, to be combined with field specific ones
+var ConnectionArgs = graphql.FieldConfigArgument{
+	"first":  &graphql.ArgumentConfig{Type: graphql.Int},
+	"after":  &graphql.ArgumentConfig{Type: graphql.String},
+	"last":   &graphql.ArgumentConfig{Type: graphql.Int},
+	"before": &graphql.ArgumentConfig{Type: graphql.String},
+}
+
+// Shared `PageInfo` type of all connections
+var PageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "PageInfo",
+	Description: "Information about pagination in a connection.",
+	Fields: graphql.Fields{
+		"hasNextPage": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.Boolean),
+			Description: "When paginating forwards, are there more items?",
+		},
+		"hasPreviousPage": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.Boolean),
+			Description: "When paginating backwards, are there more items?",
+		},
+		"startCursor": &graphql.Field{
+			Type:        graphql.String,
+			Description: "When paginating backwards, the cursor to continue.",
+		},
+		"endCursor": &graphql.Field{
+			Type:        graphql.String,
+			Description: "When paginating forwards, the cursor to continue.",
+		},
+	},
+})
+
+// Configuration of the types built by `ConnectionDefinitions`
+type ConnectionConfig struct {
+	// Prefix of the type names, the name of the node type if empty
+	Name string
+	// Type of the nodes of the connection
+	NodeType *graphql.Object
+	// Additional fields of the edge type
+	EdgeFields graphql.Fields
+	// Additional fields of the connection type
+	ConnectionFields graphql.Fields
+}
+
+// Edge and connection types of a node type
+type ConnectionTypes struct {
+	EdgeType       *graphql.Object
+	ConnectionType *graphql.Object
+}
+
+// Returns the `<Name>Edge` and `<Name>Connection` types for the node type of
+// `config`, resolving from `Edge` and `Connection` values.
+func ConnectionDefinitions(config ConnectionConfig) *ConnectionTypes {
+	name := config.Name
+	if name == "" {
+		name = config.NodeType.Name()
+	}
+
+	edgeFields := graphql.Fields{
+		"node": &graphql.Field{
+			Type:        config.NodeType,
+			Description: "The item at the end of the edge.",
+		},
+		"cursor": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.String),
+			Description: "A cursor for use in pagination.",
+		},
+	}
+	for fieldName, field := range config.EdgeFields {
+		edgeFields[fieldName] = field
+	}
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        name + "Edge",
+		Description: "An edge in a connection.",
+		Fields:      edgeFields,
+	})
+
+	connectionFields := graphql.Fields{
+		"pageInfo": &graphql.Field{
+			Type:        graphql.NewNonNull(PageInfoType),
+			Description: "Information to aid in pagination.",
+		},
+		"edges": &graphql.Field{
+			Type:        graphql.NewList(edgeType),
+			Description: "A list of edges.",
+		},
+	}
+	for fieldName, field := range config.ConnectionFields {
+		connectionFields[fieldName] = field
+	}
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        name + "Connection",
+		Description: "A connection to a list of items.",
+		Fields:      connectionFields,
+	})
+
+	return &ConnectionTypes{
+		EdgeType:       edgeType,
+		ConnectionType: connectionType,
+	}
+}
+
+// Returns the pagination arguments among the arguments of a connection field.
+func NewConnectionArguments(args map[string]interface{}) ConnectionArguments {
+	var connectionArgs ConnectionArguments
+	connectionArgs.First, _ = args["first"].(int)
+	connectionArgs.After, _ = args["after"].(string)
+	connectionArgs.Last, _ = args["last"].(int)
+	connectionArgs.Before, _ = args["before"].(string)
+	return connectionArgs
+}
+
+// Returns the opaque cursor of the item at `offset`.
+func OffsetToCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// Returns the offset encoded in a cursor made by `OffsetToCursor`.
+func CursorToOffset(cursor string) (int, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err == nil && strings.HasPrefix(string(decoded), cursorPrefix) {
+		if offset, err := strconv.Atoi(strings.TrimPrefix(string(decoded), cursorPrefix)); err == nil && offset >= 0 {
+			return offset, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid cursor %q", cursor)
+}
+
+// Returns the page of `items` selected by `args`, with offset based cursors.
+func ConnectionFromSlice(items []interface{}, args ConnectionArguments) (*Connection, error) {
+	if args.First < 0 || args.Last < 0 {
+		return nil, fmt.Errorf("first and last must not be negative")
+	}
+	start, end := 0, len(items)
+	if args.After != "" {
+		after, err := CursorToOffset(args.After)
+		if err != nil {
+			return nil, err
+		}
+		if after+1 > start {
+			start = after + 1
+		}
+	}
+	if args.Before != "" {
+		before, err := CursorToOffset(args.Before)
+		if err != nil {
+			return nil, err
+		}
+		if before < end {
+			end = before
+		}
+	}
+	if start > end {
+		start = end
+	}
+	sliceStart, sliceEnd := start, end
+	if args.First > 0 && sliceStart+args.First < sliceEnd {
+		sliceEnd = sliceStart + args.First
+	}
+	if args.Last > 0 && sliceEnd-args.Last > sliceStart {
+		sliceStart = sliceEnd - args.Last
+	}
+
+	connection := &Connection{Edges: make([]*Edge, 0, sliceEnd-sliceStart)}
+	for offset := sliceStart; offset < sliceEnd; offset++ {
+		connection.Edges = append(connection.Edges, &Edge{
+			Node:   items[offset],
+			Cursor: OffsetToCursor(offset),
+		})
+	}
+	if len(connection.Edges) > 0 {
+		connection.PageInfo.StartCursor = &connection.Edges[0].Cursor
+		connection.PageInfo.EndCursor = &connection.Edges[len(connection.Edges)-1].Cursor
+	}
+	connection.PageInfo.HasPreviousPage = args.Last > 0 && sliceStart > start
+	connection.PageInfo.HasNextPage = args.First > 0 && sliceEnd < end
+	return connection, nil
+}
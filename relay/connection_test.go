@@ -0,0 +1,130 @@
+package relay
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+var letters = []interface{}{"A", "B", "C", "D", "E"}
+
+func TestConnectionFromSlice(t *testing.T) {
+	for _, test := range []struct {
+		args     ConnectionArguments
+		expected []interface{}
+		previous bool
+		next     bool
+	}{
+		{ConnectionArguments{}, letters, false, false},
+		{ConnectionArguments{First: 2}, []interface{}{"A", "B"}, false, true},
+		{ConnectionArguments{First: 10}, letters, false, false},
+		{ConnectionArguments{First: 2, After: OffsetToCursor(1)}, []interface{}{"C", "D"}, false, true},
+		{ConnectionArguments{Last: 2}, []interface{}{"D", "E"}, true, false},
+		{ConnectionArguments{Last: 2, Before: OffsetToCursor(3)}, []interface{}{"B", "C"}, true, false},
+		{ConnectionArguments{After: OffsetToCursor(0), Before: OffsetToCursor(4)}, []interface{}{"B", "C", "D"}, false, false},
+		{ConnectionArguments{After: OffsetToCursor(10)}, []interface{}{}, false, false},
+	} {
+		connection, err := ConnectionFromSlice(letters, test.args)
+		if err != nil {
+			t.Fatalf("ConnectionFromSlice failed. Err: %v", err)
+		}
+		nodes := make([]interface{}, 0, len(connection.Edges))
+		for _, edge := range connection.Edges {
+			nodes = append(nodes, edge.Node)
+		}
+		if found, expected := toJSON(nodes), toJSON(test.expected); found != expected {
+			t.Errorf("Nodes of %+v incorrect. Found %s, expected %s", test.args, found, expected)
+		}
+		if connection.PageInfo.HasPreviousPage != test.previous || connection.PageInfo.HasNextPage != test.next {
+			t.Errorf("Page info of %+v incorrect. Found %s", test.args, toJSON(connection.PageInfo))
+		}
+	}
+}
+
+func TestConnectionFromSliceInvalid(t *testing.T) {
+	for _, args := range []ConnectionArguments{
+		{First: -1},
+		{After: "bogus"},
+		{Before: ToGlobalID("User", "1")},
+	} {
+		if _, err := ConnectionFromSlice(letters, args); err == nil {
+			t.Errorf("ConnectionFromSlice of %+v should fail", args)
+		}
+	}
+}
+
+func TestConnectionDefinitions(t *testing.T) {
+	letterType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Letter",
+		Fields: graphql.Fields{
+			"value": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+	definitions := ConnectionDefinitions(ConnectionConfig{
+		NodeType: letterType,
+		ConnectionFields: graphql.Fields{
+			"totalCount": &graphql.Field{
+				Type: graphql.Int,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return len(letters), nil
+				},
+			},
+		},
+	})
+	if name := definitions.ConnectionType.Name(); name != "LetterConnection" {
+		t.Errorf("Connection type name incorrect. Found %s, expected LetterConnection", name)
+	}
+	if name := definitions.EdgeType.Name(); name != "LetterEdge" {
+		t.Errorf("Edge type name incorrect. Found %s, expected LetterEdge", name)
+	}
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"letters": &graphql.Field{
+					Type: definitions.ConnectionType,
+					Args: ConnectionArgs,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return ConnectionFromSlice(letters, NewConnectionArguments(p.Args))
+					},
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("Schema creation failed. Err: %v", err)
+	}
+
+	result := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ letters(first: 1, after: "` + OffsetToCursor(2) + `") { totalCount edges { cursor node { value } } pageInfo { startCursor endCursor hasNextPage hasPreviousPage } } }`,
+	})
+	cursor := OffsetToCursor(3)
+	expected := `{"data":{"letters":{"edges":[{"cursor":"` + cursor + `","node":{"value":"D"}}],` +
+		`"pageInfo":{"endCursor":"` + cursor + `","hasNextPage":true,"hasPreviousPage":false,"startCursor":"` + cursor + `"},` +
+		`"totalCount":5}}}`
+	if found := toJSON(result); found != expected {
+		t.Errorf("Result incorrect. Found %s, expected %s", found, expected)
+	}
+
+	result = graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `{ letters(first: 1, after: "` + OffsetToCursor(4) + `") { edges { cursor } pageInfo { startCursor endCursor } } }`,
+	})
+	expected = `{"data":{"letters":{"edges":[],"pageInfo":{"endCursor":null,"startCursor":null}}}}`
+	if found := toJSON(result); found != expected {
+		t.Errorf("Result incorrect. Found %s, expected %s", found, expected)
+	}
+}
+
+func toJSON(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
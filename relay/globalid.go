@@ -0,0 +1,28 @@
+// Package relay provides helpers for Relay-style global IDs and cursor based
+// connections on top of graphql-go types.
+package relay
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// Returns the opaque global ID of the object `id` of type `typeName`, which is
+// `typeName:id` encoded as base64.
+func ToGlobalID(typeName, id string) string {
+	return base64.StdEncoding.EncodeToString([]byte(typeName + ":" + id))
+}
+
+// Returns the type name and the ID encoded in a global ID by `ToGlobalID`.
+func FromGlobalID(globalID string) (typeName, id string, err error) {
+	decoded, err := base64.StdEncoding.DecodeString(globalID)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid global ID %q (%v)", globalID, err)
+	}
+	parts := strings.SplitN(string(decoded), ":", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("invalid global ID %q (expected base64 of type:id)", globalID)
+	}
+	return parts[0], parts[1], nil
+}
@@ -0,0 +1,34 @@
+package relay
+
+import "testing"
+
+func TestGlobalID(t *testing.T) {
+	for _, test := range []struct {
+		typeName string
+		id       string
+	}{
+		{"User", "1"},
+		{"Post", "a:b:c"},
+		{"Tag", ""},
+	} {
+		typeName, id, err := FromGlobalID(ToGlobalID(test.typeName, test.id))
+		if err != nil {
+			t.Errorf("FromGlobalID failed. Err: %v", err)
+		}
+		if typeName != test.typeName || id != test.id {
+			t.Errorf("Global ID incorrect. Found %s:%s, expected %s:%s", typeName, id, test.typeName, test.id)
+		}
+	}
+
+	if globalID := ToGlobalID("User", "1"); globalID != "VXNlcjox" {
+		t.Errorf("Global ID incorrect. Found %s, expected VXNlcjox", globalID)
+	}
+}
+
+func TestFromGlobalIDInvalid(t *testing.T) {
+	for _, globalID := range []string{"not base64!", "VXNlcg==", "OjE="} {
+		if _, _, err := FromGlobalID(globalID); err == nil {
+			t.Errorf("FromGlobalID of %s should fail", globalID)
+		}
+	}
+}
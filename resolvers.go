@@ -0,0 +1,285 @@
+package graphqlgin
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Copies the types of a schema, wrapping the field resolvers of the object
+// types in the resolver middlewares of an app
+type resolverSchemaBuilder struct {
+	app *GraphQLApp
+	// copied object, interface and union types by name
+	types map[string]graphql.Type
+}
+
+// Returns a copy of the schema of the app whose field resolvers are wrapped in
+// its resolver middlewares. Object, interface and union types are copied, so
+// the schema of the app, and other apps sharing its types, are left untouched.
+// Scalars, enums and input objects are shared. Each resolver is wrapped once,
+// fields without a resolver wrap `graphql.DefaultResolveFn`.
+func (app *GraphQLApp) copySchemaWithResolvers() (graphql.Schema, error) {
+	builder := &resolverSchemaBuilder{app: app, types: map[string]graphql.Type{}}
+	typeMap := app.Schema.TypeMap()
+	// objects and interfaces first, the unions list the copied objects
+	for name, t := range typeMap {
+		if strings.HasPrefix(name, "__") {
+			// introspection types are resolved by graphql-go itself
+			continue
+		}
+		switch t := t.(type) {
+		case *graphql.Object:
+			builder.types[name] = builder.copyObject(t)
+		case *graphql.Interface:
+			builder.types[name] = builder.copyInterface(t)
+		}
+	}
+	var types []graphql.Type
+	for name, t := range typeMap {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		if union, ok := t.(*graphql.Union); ok {
+			builder.types[name] = builder.copyUnion(union)
+		}
+		types = append(types, builder.named(t))
+	}
+
+	config := graphql.SchemaConfig{
+		Query:      builder.object(app.Schema.QueryType()),
+		Mutation:   builder.object(app.Schema.MutationType()),
+		Types:      types,
+		Directives: app.Schema.Directives(),
+	}
+	if subscription := app.Schema.SubscriptionType(); subscription != nil {
+		config.Subscription = builder.object(subscription)
+	}
+	return graphql.NewSchema(config)
+}
+
+// Returns the copy of the object type `object`, or nil.
+func (builder *resolverSchemaBuilder) object(object *graphql.Object) *graphql.Object {
+	if object == nil {
+		return nil
+	}
+	copied, _ := builder.types[object.Name()].(*graphql.Object)
+	return copied
+}
+
+// Returns the copy of the named type `t`, or `t` itself if it is not copied.
+func (builder *resolverSchemaBuilder) named(t graphql.Type) graphql.Type {
+	if copied, ok := builder.types[t.Name()]; ok {
+		return copied
+	}
+	return t
+}
+
+// Returns the output type `t` referring to the copied types.
+func (builder *resolverSchemaBuilder) output(t graphql.Output) graphql.Output {
+	switch t := t.(type) {
+	case *graphql.List:
+		return graphql.NewList(builder.output(t.OfType))
+	case *graphql.NonNull:
+		return graphql.NewNonNull(builder.output(t.OfType))
+	}
+	return builder.named(t)
+}
+
+// Returns the configuration of the field definitions `fields` referring to the
+// copied types, wrapping the resolvers if `wrap` is true.
+func (builder *resolverSchemaBuilder) fields(fields graphql.FieldDefinitionMap, wrap bool) graphql.Fields {
+	copied := graphql.Fields{}
+	for name, field := range fields {
+		args := graphql.FieldConfigArgument{}
+		for _, arg := range field.Args {
+			args[arg.Name()] = &graphql.ArgumentConfig{
+				Type:         arg.Type,
+				DefaultValue: arg.DefaultValue,
+				Description:  arg.Description(),
+			}
+		}
+		resolve := field.Resolve
+		if wrap {
+			if resolve == nil {
+				resolve = graphql.DefaultResolveFn
+			}
+			resolve = builder.app.wrapResolver(resolve)
+		}
+		copied[name] = &graphql.Field{
+			Name:              name,
+			Type:              builder.output(field.Type),
+			Args:              args,
+			Resolve:           resolve,
+			Subscribe:         field.Subscribe,
+			DeprecationReason: field.DeprecationReason,
+			Description:       field.Description,
+		}
+	}
+	return copied
+}
+
+// Returns `resolveType` returning the copied object types.
+func (builder *resolverSchemaBuilder) resolveType(resolveType graphql.ResolveTypeFn) graphql.ResolveTypeFn {
+	if resolveType == nil {
+		return nil
+	}
+	return func(p graphql.ResolveTypeParams) *graphql.Object {
+		object := resolveType(p)
+		if copied := builder.object(object); copied != nil {
+			return copied
+		}
+		return object
+	}
+}
+
+func (builder *resolverSchemaBuilder) copyObject(object *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        object.Name(),
+		Description: object.Description(),
+		IsTypeOf:    object.IsTypeOf,
+		Interfaces: graphql.InterfacesThunk(func() []*graphql.Interface {
+			interfaces := make([]*graphql.Interface, len(object.Interfaces()))
+			for i, iface := range object.Interfaces() {
+				interfaces[i] = builder.named(iface).(*graphql.Interface)
+			}
+			return interfaces
+		}),
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return builder.fields(object.Fields(), true)
+		}),
+	})
+}
+
+func (builder *resolverSchemaBuilder) copyInterface(iface *graphql.Interface) *graphql.Interface {
+	return graphql.NewInterface(graphql.InterfaceConfig{
+		Name:        iface.Name(),
+		Description: iface.Description(),
+		ResolveType: builder.resolveType(iface.ResolveType),
+		// interface fields are never resolved
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return builder.fields(iface.Fields(), false)
+		}),
+	})
+}
+
+func (builder *resolverSchemaBuilder) copyUnion(union *graphql.Union) *graphql.Union {
+	types := make([]*graphql.Object, len(union.Types()))
+	for i, object := range union.Types() {
+		types[i] = builder.object(object)
+	}
+	return graphql.NewUnion(graphql.UnionConfig{
+		Name:        union.Name(),
+		Description: union.Description(),
+		ResolveType: builder.resolveType(union.ResolveType),
+		Types:       types,
+	})
+}
+
+// Copies the schema of the app with its resolvers wrapped in the resolver
+// middlewares, for executing the operations.
+func (app *GraphQLApp) wrapSchemaResolvers() error {
+	schema, err := app.copySchemaWithResolvers()
+	if err != nil {
+		return fmt.Errorf("could not wrap the resolvers: %w", err)
+	}
+	app.resolverSchema = schema
+	return nil
+}
+
+// Returns true if the app has resolver middlewares of `WrapResolvers`,
+// `RecoverFieldPanics` or `TraceFieldResolvers`.
+func (app *GraphQLApp) hasResolverMiddlewares() bool {
+	return len(app.resolverMiddlewares) > 0 || app.recoverFieldPanics || app.traceFieldResolvers
+}
+
+// Returns the schema executing the operations: the copy with the wrapped
+// resolvers when the app has resolver middlewares, or the schema of the app.
+func (app *GraphQLApp) executableSchema() graphql.Schema {
+	if app.hasResolverMiddlewares() {
+		return app.resolverSchema
+	}
+	return app.Schema
+}
+
+// Returns `next` wrapped in the resolver middlewares of the app: the field
+// panic recovery, then the field spans, then the `WrapResolvers` middlewares.
+// Called once per field when the schema is copied, not per resolution.
+func (app *GraphQLApp) wrapResolver(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+	for i := len(app.resolverMiddlewares) - 1; i >= 0; i-- {
+		next = app.resolverMiddlewares[i](next)
+	}
+	if app.traceFieldResolvers {
+		next = app.traceField(next)
+	}
+	if app.recoverFieldPanics {
+		next = app.recoverFieldPanic(next)
+	}
+	return next
+}
+
+// Function wrapping a field resolver, i.e. to add auth checks, timing or caching
+type ResolverMiddleware func(next graphql.FieldResolveFn) graphql.FieldResolveFn
+
+// Applies `middlewares` to the resolver of every field in the schema, replacing
+// the middlewares of previous calls. The first middleware is the outermost, so
+// it runs first. The resolvers are wrapped on a copy of the schema owned by the
+// app, so other apps sharing the schema types are not affected. The copy is
+// made when this is called, so it must be called after the schema is complete
+// and before serving any request; extensions added to `Schema` are not copied.
+func (app *GraphQLApp) WrapResolvers(middlewares ...ResolverMiddleware) error {
+	app.resolverMiddlewares = append([]ResolverMiddleware(nil), middlewares...)
+	return app.wrapSchemaResolvers()
+}
+
+// Formats a response path as a dot separated string.
+func formatPath(path *graphql.ResponsePath) string {
+	if path == nil {
+		return ""
+	}
+	parts := []string{}
+	for _, key := range path.AsArray() {
+		parts = append(parts, fmt.Sprint(key))
+	}
+	return strings.Join(parts, ".")
+}
+
+// Converts panics of `next` into generic internal errors. The `PanicHandler`
+// gets the recovered value, and the `Logger` gets it with the panicking field
+// and its path in place of the generic message.
+func (app *GraphQLApp) recoverFieldPanic(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (result interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				result = nil
+				err = fmt.Errorf("internal error (%w)", errExecutionPanicked)
+				c := GetGinContext(p.Context)
+				if c == nil {
+					return
+				}
+				if app.PanicHandler != nil {
+					app.PanicHandler(c, r, debug.Stack())
+				}
+				recordPanic(c, fmt.Sprintf(
+					"panic in resolver of %s.%s at path %s: %v",
+					p.Info.ParentType.Name(),
+					p.Info.FieldName,
+					formatPath(p.Info.Path),
+					r,
+				))
+			}
+		}()
+		return next(p)
+	}
+}
+
+// Installs a recover in every field resolver of the schema, so a panicking
+// resolver fails only its field, with a generic internal error, instead of the
+// whole operation. The `Logger` gets the field, its path and the panic value. Like `WrapResolvers`, it must be called before serving any
+// request; calling it again has no effect.
+func (app *GraphQLApp) RecoverFieldPanics() error {
+	app.recoverFieldPanics = true
+	return app.wrapSchemaResolvers()
+}
@@ -0,0 +1,275 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+type panicValue struct {
+	Reason string
+}
+
+func newPanicSchema() graphql.Schema {
+	panicSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": helloQuery,
+				"nested": &graphql.Field{
+					Type: graphql.NewObject(graphql.ObjectConfig{
+						Name: "Nested",
+						Fields: graphql.Fields{
+							"boom": &graphql.Field{
+								Type: graphql.String,
+								Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+									panic(panicValue{Reason: "kaboom"})
+								},
+							},
+						},
+					}),
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return map[string]interface{}{}, nil
+					},
+				},
+			},
+		}),
+	})
+	return panicSchema
+}
+
+func TestRecoverFieldPanicsPOST(t *testing.T) {
+	logger := &requestLogger{}
+	app := New(newPanicSchema())
+	app.RecoverFieldPanics()
+	app.Logger = logger
+	router := setupRouter(app)
+
+	type errorResponse struct {
+		Data struct {
+			Hello string `json:"hello"`
+		} `json:"data"`
+		Errors []struct {
+			Message string        `json:"message"`
+			Path    []interface{} `json:"path"`
+		} `json:"errors"`
+	}
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello nested { boom } }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Request failed. Code: %d", recorder.Code)
+	}
+	var res errorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	if res.Data.Hello != "world" {
+		t.Errorf("Response incorrect. Found %s, expected %s", res.Data.Hello, "world")
+	}
+	if len(res.Errors) != 1 {
+		t.Fatalf("Errors count incorrect. Found %d, expected %d", len(res.Errors), 1)
+	}
+	if message := res.Errors[0].Message; message != "internal error (the operation could not be completed)" {
+		t.Errorf("Error message incorrect. Found %s", message)
+	}
+	if len(logger.requests) != 1 || len(logger.requests[0].entry.Errors) != 1 {
+		t.Fatalf("Logged errors incorrect. Found %+v", logger.requests)
+	}
+	message := logger.requests[0].entry.Errors[0].Message
+	if !strings.Contains(message, "Nested.boom") || !strings.Contains(message, "nested.boom") {
+		t.Errorf("Logged error does not name the field. Found %s", message)
+	}
+	if !strings.Contains(message, "kaboom") {
+		t.Errorf("Logged error does not include the panic value. Found %s", message)
+	}
+}
+
+func TestWrapResolversPOST(t *testing.T) {
+	var calls []string
+	middleware := func(name string) ResolverMiddleware {
+		return func(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+			return func(p graphql.ResolveParams) (interface{}, error) {
+				calls = append(calls, name+":"+p.Info.FieldName)
+				return next(p)
+			}
+		}
+	}
+	upper := func(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			result, err := next(p)
+			if s, ok := result.(string); ok {
+				return strings.ToUpper(s), err
+			}
+			return result, err
+		}
+	}
+	wrappedSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": helloQuery,
+			},
+		}),
+	})
+	app := New(wrappedSchema)
+	app.WrapResolvers(middleware("outer"), middleware("inner"), upper)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if expected := `{"data":{"hello":"WORLD"}}`; recorder.Body.String() != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+	if strings.Join(calls, ",") != "outer:hello,inner:hello" {
+		t.Errorf("Middleware order incorrect. Found %v, expected %v", calls, []string{"outer:hello", "inner:hello"})
+	}
+}
+
+func TestWrapResolversPerAppPOST(t *testing.T) {
+	calls := 0
+	counter := func(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			calls++
+			return next(p)
+		}
+	}
+	wrappedSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": helloQuery,
+			},
+		}),
+	})
+	wrapped := New(wrappedSchema)
+	// wrapping again replaces the middlewares instead of stacking them
+	wrapped.WrapResolvers(counter)
+	wrapped.WrapResolvers(counter)
+	wrapped.RecoverFieldPanics()
+	wrapped.RecoverFieldPanics()
+	plain := New(wrappedSchema)
+
+	for _, app := range []*GraphQLApp{wrapped, plain} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		setupRouter(app).ServeHTTP(recorder, request)
+
+		if expected := `{"data":{"hello":"world"}}`; recorder.Body.String() != expected {
+			t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+		}
+	}
+	// the schema shared by the apps is left untouched
+	graphql.Do(graphql.Params{Schema: wrappedSchema, RequestString: "{ hello }"})
+	if calls != 1 {
+		t.Errorf("Middleware calls incorrect. Found %d, expected %d", calls, 1)
+	}
+}
+
+func TestWrapResolversOncePOST(t *testing.T) {
+	wraps := 0
+	counter := func(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+		wraps++
+		return next
+	}
+	app := New(schema)
+	app.WrapResolvers(counter)
+	fields := wraps
+	router := setupRouter(app)
+
+	for i := 0; i < 3; i++ {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if expected := `{"data":{"hello":"world"}}`; recorder.Body.String() != expected {
+			t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+		}
+	}
+	if fields == 0 || wraps != fields {
+		t.Errorf("Resolvers were wrapped per request. Found %d wraps, expected %d", wraps, fields)
+	}
+}
+
+func TestWrapResolversAbstractTypesPOST(t *testing.T) {
+	named := graphql.NewInterface(graphql.InterfaceConfig{
+		Name: "Named",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{Type: graphql.String},
+		},
+	})
+	pet := graphql.NewObject(graphql.ObjectConfig{
+		Name:       "Pet",
+		Interfaces: []*graphql.Interface{named},
+		Fields: graphql.Fields{
+			"name": &graphql.Field{Type: graphql.String},
+		},
+		IsTypeOf: func(p graphql.IsTypeOfParams) bool {
+			return true
+		},
+	})
+	named.ResolveType = func(p graphql.ResolveTypeParams) *graphql.Object {
+		return pet
+	}
+	result := graphql.NewUnion(graphql.UnionConfig{
+		Name:  "Result",
+		Types: []*graphql.Object{pet},
+		ResolveType: func(p graphql.ResolveTypeParams) *graphql.Object {
+			return pet
+		},
+	})
+	resolvePet := func(p graphql.ResolveParams) (interface{}, error) {
+		return map[string]interface{}{"name": "rex"}, nil
+	}
+	abstractSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"named":  &graphql.Field{Type: named, Resolve: resolvePet},
+				"result": &graphql.Field{Type: result, Resolve: resolvePet},
+			},
+		}),
+		Types: []graphql.Type{pet},
+	})
+	app := New(abstractSchema)
+	upper := func(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+		return func(p graphql.ResolveParams) (interface{}, error) {
+			result, err := next(p)
+			if s, ok := result.(string); ok {
+				return strings.ToUpper(s), err
+			}
+			return result, err
+		}
+	}
+	if err := app.WrapResolvers(upper); err != nil {
+		t.Fatalf("Wrapping resolvers failed. Err: %v", err)
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ named { name } result { ... on Pet { name } } }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if expected := `{"data":{"named":{"name":"REX"},"result":{"name":"REX"}}}`; recorder.Body.String() != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+}
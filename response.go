@@ -0,0 +1,62 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Buffers larger than this are dropped instead of being returned to the pool,
+// so a single huge response doesn't pin its memory
+const maxPooledBufferSize = 1 << 20
+
+// Buffers reused to encode the responses
+var responseBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+func getResponseBuffer() *bytes.Buffer {
+	buffer := responseBufferPool.Get().(*bytes.Buffer)
+	buffer.Reset()
+	return buffer
+}
+
+func putResponseBuffer(buffer *bytes.Buffer) {
+	if buffer.Cap() <= maxPooledBufferSize {
+		responseBufferPool.Put(buffer)
+	}
+}
+
+// Encodes `reply` into a pooled buffer with the `ResponseEncoder`, or as JSON
+// like `c.JSON` and `c.IndentedJSON`, then writes it with `status`. Nothing is
+// written but a 500 status if the encoding fails.
+func (app *GraphQLApp) writeReply(c *gin.Context, status int, reply interface{}, indent bool) {
+	buffer := getResponseBuffer()
+	defer putResponseBuffer(buffer)
+
+	var err error
+	if app.ResponseEncoder != nil {
+		err = app.ResponseEncoder(buffer, reply)
+	} else {
+		encoder := json.NewEncoder(buffer)
+		if indent {
+			encoder.SetIndent("", "    ")
+		}
+		if err = encoder.Encode(reply); err == nil {
+			// drop the new line added by the encoder
+			buffer.Truncate(buffer.Len() - 1)
+		}
+	}
+	if err != nil {
+		c.Error(err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	c.Status(status)
+	c.Writer.Write(buffer.Bytes())
+}
@@ -0,0 +1,90 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+func TestConcurrentRepliesPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			recorder := httptest.NewRecorder()
+			body := fmt.Sprintf(`{"query": "{ double(value: %d) }"}`, i)
+			request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+			request.Header.Add("Content-Type", "application/json")
+
+			router.ServeHTTP(recorder, request)
+
+			expected := fmt.Sprintf(`{"data":{"double":%d}}`, 2*i)
+			if res := recorder.Body.String(); res != expected {
+				t.Errorf("Response incorrect. Found %s, expected %s", res, expected)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestResponseEncoderErrorPOST(t *testing.T) {
+	app := New(schema)
+	app.ResponseEncoder = func(w io.Writer, v interface{}) error {
+		w.Write([]byte(`{"data":`))
+		return errors.New("encoding failed")
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusInternalServerError)
+	}
+	if body := recorder.Body.String(); body != "" {
+		t.Errorf("Partial response written. Found %s", body)
+	}
+}
+
+// Result of a typical list query to encode in the benchmarks
+var benchmarkResult = func() *graphql.Result {
+	items := make([]interface{}, 100)
+	for i := range items {
+		items[i] = map[string]interface{}{"id": i, "name": fmt.Sprintf("item %d", i)}
+	}
+	return &graphql.Result{Data: map[string]interface{}{"items": items}}
+}()
+
+func BenchmarkReplyGinJSON(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.JSON(http.StatusOK, benchmarkResult)
+	}
+}
+
+func BenchmarkReplyPooled(b *testing.B) {
+	app := New(schema)
+	request, _ := http.NewRequest("POST", "/", nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = request
+		app.respond(c, http.StatusOK, benchmarkResult)
+	}
+}
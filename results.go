@@ -0,0 +1,28 @@
+package graphqlgin
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+// Extension key for the name of the operation a result belongs to
+const OperationNameExtension = "operationName"
+
+// Sets `key` of the result extensions to `value`.
+func setResultExtension(result *graphql.Result, key string, value interface{}) {
+	if result.Extensions == nil {
+		result.Extensions = map[string]interface{}{}
+	}
+	result.Extensions[key] = value
+}
+
+// Returns the name of the requested operation, falling back to the name of the
+// operation found in the document when the request doesn't specify one.
+func requestOperationName(query *queryDocument) string {
+	if query.params.OperationName != "" {
+		return query.params.OperationName
+	}
+	if _, operation, err := query.Operation(); err == nil && operation.Name != nil {
+		return operation.Name.Value
+	}
+	return ""
+}
@@ -0,0 +1,42 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type operationNameResponse struct {
+	Extensions map[string]interface{} `json:"extensions"`
+}
+
+func TestResultOperationNamePOST(t *testing.T) {
+	app := New(schema)
+	app.ResultOperationName = true
+	router := setupRouter(app)
+
+	for _, tc := range []struct {
+		body     string
+		expected string
+	}{
+		{`{"query": "query a { hello } query b { hello }", "operationName": "b"}`, "b"},
+		{`{"query": "query inferred { hello }"}`, "inferred"},
+		{`{"query": "{ hello }"}`, ""},
+	} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(tc.body))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		var res operationNameResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+			t.Errorf("Response unmarshal failed. Err: %v", err)
+		}
+		if name := res.Extensions[OperationNameExtension]; name != tc.expected {
+			t.Errorf("Operation name incorrect. Found %v, expected %v", name, tc.expected)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package graphqlgin
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Function to create the root object passed to the root resolvers as
+// `graphql.ResolveParams.Source`
+type RootObjectProviderFn func(c *gin.Context) map[string]interface{}
+
+// Returns the root object of the request, nil without a `RootObjectProvider`.
+func (app *GraphQLApp) rootObject(c *gin.Context) map[string]interface{} {
+	if app.RootObjectProvider == nil {
+		return nil
+	}
+	return app.RootObjectProvider(c)
+}
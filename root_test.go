@@ -0,0 +1,62 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+var rootSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"viewer": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					root, _ := p.Source.(map[string]interface{})
+					return root["viewer"], nil
+				},
+			},
+		},
+	}),
+})
+
+func TestRootObjectProviderPOST(t *testing.T) {
+	app := New(rootSchema)
+	app.RootObjectProvider = func(c *gin.Context) map[string]interface{} {
+		return map[string]interface{}{"viewer": c.GetHeader("X-Viewer")}
+	}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ viewer }"}`))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add("X-Viewer", "jane")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"viewer":"jane"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestRootObjectProviderUnsetPOST(t *testing.T) {
+	app := New(rootSchema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ viewer }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"viewer":null}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
@@ -0,0 +1,113 @@
+package graphqlgin
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+// Version information of the schema served by `SchemaVersionHandler`
+type SchemaVersion struct {
+	Version string `json:"version"`
+	Hash    string `json:"hash"`
+}
+
+// Returns the sorted lines describing the types of `schema` and their members,
+// ignoring descriptions.
+func schemaShape(schema *graphql.Schema) []string {
+	var lines []string
+	for name, t := range schema.TypeMap() {
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		switch t := t.(type) {
+		case *graphql.Object:
+			lines = append(lines, "type "+name)
+			lines = append(lines, fieldShapes(name, t.Fields())...)
+			for _, iface := range t.Interfaces() {
+				lines = append(lines, fmt.Sprintf("type %s implements %s", name, iface.Name()))
+			}
+		case *graphql.Interface:
+			lines = append(lines, "interface "+name)
+			lines = append(lines, fieldShapes(name, t.Fields())...)
+		case *graphql.Union:
+			lines = append(lines, "union "+name)
+			for _, member := range t.Types() {
+				lines = append(lines, fmt.Sprintf("union %s member %s", name, member.Name()))
+			}
+		case *graphql.Enum:
+			lines = append(lines, "enum "+name)
+			for _, value := range t.Values() {
+				lines = append(lines, fmt.Sprintf("enum %s value %s deprecated %q", name, value.Name, value.DeprecationReason))
+			}
+		case *graphql.InputObject:
+			lines = append(lines, "input "+name)
+			for fieldName, field := range t.Fields() {
+				lines = append(lines, fmt.Sprintf("input %s.%s: %s = %v", name, fieldName, field.Type, field.DefaultValue))
+			}
+		case *graphql.Scalar:
+			lines = append(lines, "scalar "+name)
+		}
+	}
+	for _, root := range []*graphql.Object{schema.QueryType(), schema.MutationType(), schema.SubscriptionType()} {
+		if root != nil {
+			lines = append(lines, "root "+root.Name())
+		}
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// Returns the lines describing the fields of type `typeName` and their arguments.
+func fieldShapes(typeName string, fields graphql.FieldDefinitionMap) []string {
+	var lines []string
+	for fieldName, field := range fields {
+		lines = append(lines, fmt.Sprintf("field %s.%s: %s deprecated %q", typeName, fieldName, field.Type, field.DeprecationReason))
+		for _, arg := range field.Args {
+			lines = append(lines, fmt.Sprintf("arg %s.%s(%s: %s = %v)", typeName, fieldName, arg.Name(), arg.Type, arg.DefaultValue))
+		}
+	}
+	return lines
+}
+
+// Returns the SHA-256 hex digest of the shape of `schema`. It changes whenever a
+// type, field, argument or enum value is added, removed or changed, but not when
+// only descriptions change.
+func SchemaHash(schema graphql.Schema) string {
+	sum := sha256.Sum256([]byte(strings.Join(schemaShape(&schema), "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Returns a `gin.HandlerFunc` replying with the `SchemaVersion` of the app and
+// the hash of its schema, so clients can poll it to detect schema changes.
+func (app *GraphQLApp) SchemaVersionHandler() gin.HandlerFunc {
+	version := SchemaVersion{
+		Version: app.SchemaVersion,
+		Hash:    SchemaHash(app.Schema),
+	}
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, version)
+	}
+}
+
+// Adds `types` to the schema of the app like `New` adds `UploadType`, i.e. for
+// scalars only used by variables and not reachable from the root types. Must
+// be called before any handler is created, as the schema is not safe for
+// concurrent modification while serving requests.
+func (app *GraphQLApp) AppendTypes(types ...graphql.Type) error {
+	for _, t := range types {
+		if t == nil {
+			return fmt.Errorf("could not register a nil type")
+		}
+		if err := app.Schema.AppendType(t); err != nil {
+			return fmt.Errorf("could not register type %s: %w", t.Name(), err)
+		}
+	}
+	return nil
+}
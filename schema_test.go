@@ -0,0 +1,97 @@
+package graphqlgin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+func TestSchemaVersionHandler(t *testing.T) {
+	app := New(schema)
+	app.SchemaVersion = "2021.06.1"
+	router := gin.Default()
+	router.GET("/schema/version", app.SchemaVersionHandler())
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/schema/version", nil)
+
+	router.ServeHTTP(recorder, request)
+
+	var version SchemaVersion
+	if err := json.Unmarshal(recorder.Body.Bytes(), &version); err != nil {
+		t.Fatalf("Response unmarshal failed. Err: %v", err)
+	}
+	if version.Version != "2021.06.1" {
+		t.Errorf("Version incorrect. Found %s, expected %s", version.Version, "2021.06.1")
+	}
+	if version.Hash != SchemaHash(app.Schema) || len(version.Hash) != 64 {
+		t.Errorf("Hash incorrect. Found %s, expected %s", version.Hash, SchemaHash(app.Schema))
+	}
+}
+
+func TestSchemaHash(t *testing.T) {
+	newSchema := func(argType graphql.Input, description string) graphql.Schema {
+		s, _ := graphql.NewSchema(graphql.SchemaConfig{
+			Query: graphql.NewObject(graphql.ObjectConfig{
+				Name: "Query",
+				Fields: graphql.Fields{
+					"hello": &graphql.Field{
+						Type:        graphql.String,
+						Description: description,
+						Args: graphql.FieldConfigArgument{
+							"name": &graphql.ArgumentConfig{Type: argType},
+						},
+					},
+				},
+			}),
+		})
+		return s
+	}
+
+	hash := SchemaHash(newSchema(graphql.String, "a"))
+	if again := SchemaHash(newSchema(graphql.String, "a")); again != hash {
+		t.Errorf("Hash not stable. Found %s, expected %s", again, hash)
+	}
+	if described := SchemaHash(newSchema(graphql.String, "b")); described != hash {
+		t.Errorf("Hash changed with description. Found %s, expected %s", described, hash)
+	}
+	if changed := SchemaHash(newSchema(graphql.NewNonNull(graphql.String), "a")); changed == hash {
+		t.Errorf("Hash not changed with argument type")
+	}
+}
+
+func TestAppendTypes(t *testing.T) {
+	appendSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name:   "Query",
+			Fields: graphql.Fields{"hello": helloQuery},
+		}),
+	})
+	uuidType := graphql.NewScalar(graphql.ScalarConfig{
+		Name:      "UUID",
+		Serialize: func(value interface{}) interface{} { return value },
+	})
+	app := New(appendSchema)
+
+	if err := app.AppendTypes(uuidType, UploadType); err != nil {
+		t.Errorf("Types not registered. Err: %v", err)
+	}
+	if found := app.Schema.Type("UUID"); found != uuidType {
+		t.Errorf("Registered type incorrect. Found %v, expected %v", found, uuidType)
+	}
+
+	clashing := graphql.NewScalar(graphql.ScalarConfig{
+		Name:      "UUID",
+		Serialize: func(value interface{}) interface{} { return value },
+	})
+	if err := app.AppendTypes(clashing); err == nil {
+		t.Errorf("Type with a taken name registered")
+	}
+	if err := app.AppendTypes(nil); err == nil {
+		t.Errorf("Nil type registered")
+	}
+}
@@ -0,0 +1,63 @@
+package graphqlgin
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Key of the request scope in the resolver context
+const requestScopeKey contextKey = "RequestScope"
+
+// Goroutine safe bag of values living as long as a request, i.e. dataloaders
+type RequestScope struct {
+	mu     sync.Mutex
+	values map[interface{}]interface{}
+}
+
+// Returns the value of `key`, if it is set.
+func (scope *RequestScope) Get(key interface{}) (interface{}, bool) {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	value, ok := scope.values[key]
+	return value, ok
+}
+
+// Sets the value of `key`.
+func (scope *RequestScope) Set(key interface{}, value interface{}) {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	scope.values[key] = value
+}
+
+// Returns the value of `key`, setting it to the result of `create` first if it
+// is not set. `create` is called at most once per key, even by concurrent
+// resolvers, so it is safe to create loaders lazily.
+func (scope *RequestScope) GetOrCreate(key interface{}, create func() interface{}) interface{} {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	value, ok := scope.values[key]
+	if !ok {
+		value = create()
+		scope.values[key] = value
+	}
+	return value
+}
+
+// Returns a `ContextProviderFn` that adds a new `RequestScope` to the context
+// passed down to resolver functions for each request.
+func NewRequestScopeProvider() ContextProviderFn {
+	return func(c *gin.Context, ctx context.Context) context.Context {
+		return context.WithValue(ctx, requestScopeKey, &RequestScope{
+			values: map[interface{}]interface{}{},
+		})
+	}
+}
+
+// Returns the `RequestScope` of the request, or nil if the provider of
+// `NewRequestScopeProvider` is not used.
+func GetRequestScope(ctx context.Context) *RequestScope {
+	scope, _ := ctx.Value(requestScopeKey).(*RequestScope)
+	return scope
+}
@@ -0,0 +1,73 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+type scopeLoaderKey struct{}
+
+func TestRequestScopePOST(t *testing.T) {
+	var scopes []*RequestScope
+	created := 0
+	scopeSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"loader": &graphql.Field{
+					Type: graphql.Int,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						scope := GetRequestScope(p.Context)
+						scopes = append(scopes, scope)
+						loader := scope.GetOrCreate(scopeLoaderKey{}, func() interface{} {
+							created++
+							return created
+						})
+						return loader, nil
+					},
+				},
+			},
+		}),
+	})
+	app := New(scopeSchema, NewRequestScopeProvider())
+	router := setupRouter(app)
+
+	for _, expected := range []string{
+		`{"data":{"a":1,"b":1}}`,
+		`{"data":{"a":2,"b":2}}`,
+	} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ a: loader b: loader }"}`))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if body := recorder.Body.String(); body != expected {
+			t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+		}
+	}
+	if len(scopes) != 4 || scopes[0] == nil || scopes[0] != scopes[1] || scopes[1] == scopes[2] || scopes[2] != scopes[3] {
+		t.Errorf("Request scopes incorrect. Found %v, expected one scope per request", scopes)
+	}
+}
+
+func TestRequestScope(t *testing.T) {
+	if scope := GetRequestScope(context.Background()); scope != nil {
+		t.Errorf("Request scope without provider incorrect. Found %v, expected nil", scope)
+	}
+
+	ctx := NewRequestScopeProvider()(nil, context.Background())
+	scope := GetRequestScope(ctx)
+	if _, ok := scope.Get("user"); ok {
+		t.Errorf("Value of new request scope found")
+	}
+	scope.Set("user", "alice")
+	if value, ok := scope.Get("user"); !ok || value != "alice" {
+		t.Errorf("Request scope value incorrect. Found %v, expected %v", value, "alice")
+	}
+}
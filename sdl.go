@@ -0,0 +1,405 @@
+package graphqlgin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+// Introspection query run by `SDLHandler` for the `json=1` variant
+const introspectionQuery = `
+query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType {
+                kind
+                name
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+// Scalars defined by the GraphQL specification, which are not printed
+var specifiedScalars = map[string]bool{
+	"String":  true,
+	"Int":     true,
+	"Float":   true,
+	"Boolean": true,
+	"ID":      true,
+}
+
+// Argument or input object field to print
+type sdlInputValue struct {
+	name         string
+	description  string
+	valueType    graphql.Input
+	defaultValue interface{}
+}
+
+// Returns the schema definition language (SDL) document of `schema`. Types,
+// fields, arguments and enum values are sorted by name, so the output is stable
+// and can be diffed.
+func PrintSchema(schema graphql.Schema) string {
+	return printSchema(schema, nil)
+}
+
+// Returns the SDL document of `schema`, applying the `@feature` directives of
+// `featureFields` to their fields.
+func printSchema(schema graphql.Schema, featureFields map[string]string) string {
+	var blocks []string
+	if block := printSchemaDefinition(&schema); block != "" {
+		blocks = append(blocks, block)
+	}
+
+	specifiedDirectives := map[string]bool{}
+	for _, directive := range graphql.SpecifiedDirectives {
+		specifiedDirectives[directive.Name] = true
+	}
+	for _, directive := range schema.Directives() {
+		if !specifiedDirectives[directive.Name] {
+			blocks = append(blocks, printDirective(directive))
+		}
+	}
+
+	typeMap := schema.TypeMap()
+	names := make([]string, 0, len(typeMap))
+	for name := range typeMap {
+		if !strings.HasPrefix(name, "__") && !specifiedScalars[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if block := printType(typeMap[name], featureFields); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	return strings.Join(blocks, "\n\n") + "\n"
+}
+
+// Returns the schema definition, or an empty string when the root types have
+// their default names.
+func printSchemaDefinition(schema *graphql.Schema) string {
+	roots := []struct {
+		operation string
+		root      *graphql.Object
+		name      string
+	}{
+		{"query", schema.QueryType(), "Query"},
+		{"mutation", schema.MutationType(), "Mutation"},
+		{"subscription", schema.SubscriptionType(), "Subscription"},
+	}
+	custom := false
+	var lines []string
+	for _, root := range roots {
+		if root.root == nil {
+			continue
+		}
+		custom = custom || root.root.Name() != root.name
+		lines = append(lines, fmt.Sprintf("  %s: %s", root.operation, root.root.Name()))
+	}
+	if !custom {
+		return ""
+	}
+	return "schema {\n" + strings.Join(lines, "\n") + "\n}"
+}
+
+// Returns the definition of a custom directive.
+func printDirective(directive *graphql.Directive) string {
+	var args []sdlInputValue
+	for _, arg := range directive.Args {
+		args = append(args, sdlInputValue{arg.Name(), arg.Description(), arg.Type, arg.DefaultValue})
+	}
+	return printDescription("", directive.Description) +
+		"directive @" + directive.Name + printArgs("", args) +
+		" on " + strings.Join(directive.Locations, " | ")
+}
+
+// Returns the definition of a named type, applying the `@feature` directives of
+// `featureFields` to its fields.
+func printType(t graphql.Type, featureFields map[string]string) string {
+	switch t := t.(type) {
+	case *graphql.Scalar:
+		return printDescription("", t.Description()) + "scalar " + t.Name()
+	case *graphql.Object:
+		definition := "type " + t.Name()
+		if interfaces := t.Interfaces(); len(interfaces) > 0 {
+			names := make([]string, len(interfaces))
+			for i, iface := range interfaces {
+				names[i] = iface.Name()
+			}
+			definition += " implements " + strings.Join(names, " & ")
+		}
+		return printDescription("", t.Description()) + definition + printFields(t.Name(), t.Fields(), featureFields)
+	case *graphql.Interface:
+		return printDescription("", t.Description()) + "interface " + t.Name() + printFields(t.Name(), t.Fields(), featureFields)
+	case *graphql.Union:
+		members := make([]string, len(t.Types()))
+		for i, member := range t.Types() {
+			members[i] = member.Name()
+		}
+		return printDescription("", t.Description()) + "union " + t.Name() + " = " + strings.Join(members, " | ")
+	case *graphql.Enum:
+		// sort a copy, the values are the ones of the live schema
+		values := append([]*graphql.EnumValueDefinition(nil), t.Values()...)
+		sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+		lines := make([]string, len(values))
+		for i, value := range values {
+			lines[i] = printDescription("  ", value.Description) + "  " + value.Name + printDeprecation(value.DeprecationReason)
+		}
+		return printDescription("", t.Description()) + "enum " + t.Name() + printBlock(lines)
+	case *graphql.InputObject:
+		fieldMap := t.Fields()
+		names := make([]string, 0, len(fieldMap))
+		for name := range fieldMap {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		lines := make([]string, len(names))
+		for i, name := range names {
+			field := fieldMap[name]
+			lines[i] = printInputValue("  ", sdlInputValue{field.Name(), field.Description(), field.Type, field.DefaultValue})
+		}
+		return printDescription("", t.Description()) + "input " + t.Name() + printBlock(lines)
+	}
+	return ""
+}
+
+// Returns the fields of the object or interface type `parent` as a block.
+func printFields(parent string, fields graphql.FieldDefinitionMap, featureFields map[string]string) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := make([]string, len(names))
+	for i, name := range names {
+		field := fields[name]
+		var args []sdlInputValue
+		for _, arg := range field.Args {
+			args = append(args, sdlInputValue{arg.Name(), arg.Description(), arg.Type, arg.DefaultValue})
+		}
+		lines[i] = printDescription("  ", field.Description) +
+			"  " + name + printArgs("  ", args) + ": " + field.Type.String() +
+			printDeprecation(field.DeprecationReason)
+		if flag, ok := featureFields[parent+"."+name]; ok {
+			lines[i] += " @" + FeatureDirective.Name + "(flag: " + printString(flag) + ")"
+		}
+	}
+	return printBlock(lines)
+}
+
+// Returns the arguments sorted by name, on separate lines if any of them has a
+// description.
+func printArgs(indent string, args []sdlInputValue) string {
+	if len(args) == 0 {
+		return ""
+	}
+	sort.Slice(args, func(i, j int) bool { return args[i].name < args[j].name })
+	multiline := false
+	for _, arg := range args {
+		multiline = multiline || arg.description != ""
+	}
+	lines := make([]string, len(args))
+	for i, arg := range args {
+		if multiline {
+			lines[i] = printInputValue(indent+"  ", arg)
+		} else {
+			lines[i] = printInputValue("", arg)
+		}
+	}
+	if multiline {
+		return "(\n" + strings.Join(lines, "\n") + "\n" + indent + ")"
+	}
+	return "(" + strings.Join(lines, ", ") + ")"
+}
+
+// Returns an argument or input field with its description and default value.
+func printInputValue(indent string, value sdlInputValue) string {
+	line := printDescription(indent, value.description) + indent + value.name + ": " + value.valueType.String()
+	if value.defaultValue != nil {
+		line += " = " + printValue(value.defaultValue, value.valueType)
+	}
+	return line
+}
+
+// Returns `lines` enclosed in braces, or an empty string without lines.
+func printBlock(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return " {\n" + strings.Join(lines, "\n") + "\n}"
+}
+
+// Returns `description` as a block string followed by a new line.
+func printDescription(indent string, description string) string {
+	if description == "" {
+		return ""
+	}
+	description = strings.ReplaceAll(description, `"""`, `\"""`)
+	if !strings.Contains(description, "\n") {
+		return indent + `"""` + description + `"""` + "\n"
+	}
+	lines := strings.Split(description, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = indent + line
+		}
+	}
+	return indent + `"""` + "\n" + strings.Join(lines, "\n") + "\n" + indent + `"""` + "\n"
+}
+
+// Returns the deprecated directive for `reason`, if any.
+func printDeprecation(reason string) string {
+	switch reason {
+	case "":
+		return ""
+	case graphql.DefaultDeprecationReason:
+		return " @deprecated"
+	}
+	return " @deprecated(reason: " + printString(reason) + ")"
+}
+
+// Returns the GraphQL literal of the default value `value` of type `t`.
+func printValue(value interface{}, t graphql.Input) string {
+	if value == nil {
+		return "null"
+	}
+	switch t := t.(type) {
+	case *graphql.NonNull:
+		return printValue(value, t.OfType)
+	case *graphql.List:
+		items := reflect.ValueOf(value)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			return printValue(value, t.OfType)
+		}
+		printed := make([]string, items.Len())
+		for i := range printed {
+			printed[i] = printValue(items.Index(i).Interface(), t.OfType)
+		}
+		return "[" + strings.Join(printed, ", ") + "]"
+	case *graphql.Enum:
+		for _, enumValue := range t.Values() {
+			if reflect.DeepEqual(enumValue.Value, value) {
+				return enumValue.Name
+			}
+		}
+	case *graphql.InputObject:
+		if fields, ok := value.(map[string]interface{}); ok {
+			fieldMap := t.Fields()
+			names := make([]string, 0, len(fields))
+			for name := range fields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			printed := make([]string, 0, len(names))
+			for _, name := range names {
+				if field, ok := fieldMap[name]; ok {
+					printed = append(printed, name+": "+printValue(fields[name], field.Type))
+				}
+			}
+			return "{" + strings.Join(printed, ", ") + "}"
+		}
+	}
+	if s, ok := value.(string); ok {
+		return printString(s)
+	}
+	return fmt.Sprint(value)
+}
+
+// Returns `s` as a quoted GraphQL string.
+func printString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// Returns a `gin.HandlerFunc` replying with the SDL of the current schema as
+// plain text, or with the result of an introspection query with `json=1`, i.e.
+// to diff schema changes in CI.
+func (app *GraphQLApp) SDLHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Query("json") == "1" {
+			c.JSON(http.StatusOK, graphql.Do(graphql.Params{
+				Schema:        app.Schema,
+				RequestString: introspectionQuery,
+				Context:       c.Request.Context(),
+			}))
+			return
+		}
+		c.String(http.StatusOK, printSchema(app.Schema, app.appliedFeatureDirectives()))
+	}
+}
@@ -0,0 +1,148 @@
+package graphqlgin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+var sdlColorEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "Color",
+	Values: graphql.EnumValueConfigMap{
+		"RED":   &graphql.EnumValueConfig{Value: 0},
+		"GREEN": &graphql.EnumValueConfig{Value: 1, DeprecationReason: "use RED"},
+	},
+})
+
+var sdlFilterInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "Filter",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"color": &graphql.InputObjectFieldConfig{Type: sdlColorEnum, DefaultValue: 1},
+		"tags":  &graphql.InputObjectFieldConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
+	},
+})
+
+var sdlSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Query",
+		Description: "Root of all queries",
+		Fields: graphql.Fields{
+			"hello": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.String),
+				Description: "Greets the caller",
+				Args: graphql.FieldConfigArgument{
+					"name":  &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "world"},
+					"times": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+			},
+			"colors": &graphql.Field{
+				Type: graphql.NewList(sdlColorEnum),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: sdlFilterInput, Description: "Colors to select"},
+				},
+			},
+			"old": &graphql.Field{Type: graphql.String, DeprecationReason: graphql.DefaultDeprecationReason},
+		},
+	}),
+})
+
+const sdlExpected = `enum Color {
+  GREEN @deprecated(reason: "use RED")
+  RED
+}
+
+input Filter {
+  color: Color = GREEN
+  tags: [String!]
+}
+
+"""Root of all queries"""
+type Query {
+  colors(
+    """Colors to select"""
+    filter: Filter
+  ): [Color]
+  """Greets the caller"""
+  hello(name: String = "world", times: Int): String!
+  old: String @deprecated
+}
+
+"""File upload scalar"""
+scalar Upload
+`
+
+func TestPrintSchema(t *testing.T) {
+	app := New(sdlSchema)
+
+	if sdl := PrintSchema(app.Schema); sdl != sdlExpected {
+		t.Errorf("Schema SDL incorrect. Found %s, expected %s", sdl, sdlExpected)
+	}
+}
+
+func TestPrintSchemaKeepsEnumValues(t *testing.T) {
+	enum := graphql.NewEnum(graphql.EnumConfig{
+		Name: "Letter",
+		Values: graphql.EnumValueConfigMap{
+			"C": &graphql.EnumValueConfig{Value: 2},
+			"B": &graphql.EnumValueConfig{Value: 1},
+			"A": &graphql.EnumValueConfig{Value: 0},
+		},
+	})
+	names := func() []string {
+		names := []string{}
+		for _, value := range enum.Values() {
+			names = append(names, value.Name)
+		}
+		return names
+	}
+	before := names()
+	printType(enum, nil)
+
+	if after := names(); strings.Join(after, ",") != strings.Join(before, ",") {
+		t.Errorf("Enum values reordered. Found %v, expected %v", after, before)
+	}
+}
+
+func TestSDLHandler(t *testing.T) {
+	app := New(sdlSchema)
+	router := gin.Default()
+	router.GET("/schema", app.SDLHandler())
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("GET", "/schema", nil)
+
+	router.ServeHTTP(recorder, request)
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/plain; charset=utf-8" {
+		t.Errorf("Content type incorrect. Found %s, expected %s", contentType, "text/plain; charset=utf-8")
+	}
+	if body := recorder.Body.String(); body != sdlExpected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, sdlExpected)
+	}
+
+	recorder = httptest.NewRecorder()
+	request, _ = http.NewRequest("GET", "/schema?json=1", nil)
+
+	router.ServeHTTP(recorder, request)
+
+	var res struct {
+		Data struct {
+			Schema struct {
+				QueryType struct {
+					Name string `json:"name"`
+				} `json:"queryType"`
+			} `json:"__schema"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	if name := res.Data.Schema.QueryType.Name; name != "Query" {
+		t.Errorf("Introspected query type incorrect. Found %s, expected %s", name, "Query")
+	}
+}
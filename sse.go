@@ -0,0 +1,114 @@
+package graphqlgin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// Factory function to create a `gin.HandlerFunc` serving GraphQL subscriptions
+// over Server-Sent Events, for clients that can't use WebSockets.
+//
+// The subscription operation is read from a JSON POST body. Each result is
+// streamed as a `data:` event, followed by an `event: complete` event when the
+// source is exhausted. The subscription stops when the client disconnects. The
+// context providers of the app, followed by `contextProviders`, are called
+// before subscribing. The operation goes through the same query loading and
+// checks as the operations of `Handler`.
+func (app *GraphQLApp) SSEHandler(contextProviders ...ContextProviderFn) gin.HandlerFunc {
+	providers := app.handlerProviders(contextProviders)
+
+	return func(c *gin.Context) {
+		var params GraphQLRequestParams
+		if err := c.ShouldBindJSON(&params); err != nil {
+			c.JSON(
+				http.StatusBadRequest,
+				graphqlErrorReply("invalid request body", err),
+			)
+			return
+		}
+
+		query := &queryDocument{params: &params}
+
+		// log the subscription once it ends
+		logCtx := c.Request.Context()
+		var errs []gqlerrors.FormattedError
+		if app.Logger != nil || app.SummaryLogger != nil {
+			started := time.Now()
+			defer func() {
+				app.logSubscription(c, logCtx, started, query, errs)
+			}()
+		}
+
+		// stop the subscription when the client disconnects or the stream ends
+		streamCtx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+		ctx, err := app.provideContext(c, streamCtx, providers)
+		if err != nil {
+			// reply like `Handler` to requests rejected by a provider
+			status, reply := http.StatusServiceUnavailable, graphqlErrorReply("could not create resolver context", err)
+			var providerErr *ContextProviderError
+			if errors.As(err, &providerErr) {
+				status, reply = app.contextProviderErrorStatus(), graphqlErrorReply("request rejected", providerErr.Err)
+			}
+			errs = replyErrors(reply)
+			c.JSON(status, reply)
+			return
+		}
+		logCtx = ctx
+
+		// load and check the operation like the operations of `Handler`
+		ctx, status, reply := app.prepareQuery(c, ctx, query)
+		if reply == nil {
+			status, reply = app.checkQuery(c, ctx, query)
+		}
+		if reply != nil {
+			errs = replyErrors(reply)
+			c.JSON(status, reply)
+			return
+		}
+
+		results := graphql.Subscribe(graphql.Params{
+			Schema:         app.executableSchema(),
+			RequestString:  params.RequestString,
+			RootObject:     app.rootObject(c),
+			OperationName:  params.OperationName,
+			VariableValues: params.VariableValues,
+			Context:        ctx,
+		})
+		// the source blocks sending results until it sees the cancellation
+		defer drainResults(results)
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Status(http.StatusOK)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case result, ok := <-results:
+				if !ok {
+					fmt.Fprint(c.Writer, "event: complete\ndata:\n\n")
+					c.Writer.Flush()
+					return
+				}
+				app.formatResultErrors(ctx, params.RequestString, result)
+				errs = append(errs, result.Errors...)
+				data, err := json.Marshal(result)
+				if err != nil {
+					return
+				}
+				fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+				c.Writer.Flush()
+			}
+		}
+	}
+}
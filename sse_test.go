@@ -0,0 +1,111 @@
+package graphqlgin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSSEHandler(t *testing.T) {
+	app := New(subscriptionSchema)
+	router := gin.Default()
+	router.POST("/sse", app.SSEHandler(prefixProvider))
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/sse?prefix=n", bytes.NewBufferString(`{"query": "subscription { counter(to: 2) }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("Content type incorrect. Found %s, expected text/event-stream", contentType)
+	}
+	expected := "data: {\"data\":{\"counter\":\"n*\"}}\n\n" +
+		"data: {\"data\":{\"counter\":\"n**\"}}\n\n" +
+		"event: complete\ndata:\n\n"
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %q, expected %q", body, expected)
+	}
+}
+
+func TestSSEHandlerChecks(t *testing.T) {
+	app := New(subscriptionSchema)
+	app.TrustedQueriesOnly = true
+	router := gin.Default()
+	router.POST("/sse", app.SSEHandler())
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/sse", bytes.NewBufferString(`{"query": "subscription { counter(to: 2) }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"errors":[{"message":"query not trusted (operations must be sent by the hash of a trusted query)"}]}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestSSEHandlerProviderErrorStatus(t *testing.T) {
+	app := New(subscriptionSchema, ContextProviderWithError(authProvider))
+	app.ContextProviderErrorStatus = http.StatusUnauthorized
+	router := gin.Default()
+	router.POST("/sse", app.SSEHandler())
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/sse", bytes.NewBufferString(`{"query": "subscription { counter(to: 2) }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusUnauthorized)
+	}
+	expected := `{"errors":[{"message":"request rejected (invalid token)"}]}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestSSEHandlerDisconnect(t *testing.T) {
+	stopped := make(chan struct{})
+	app := New(subscriptionSchema, func(c *gin.Context, ctx context.Context) context.Context {
+		go func() {
+			<-ctx.Done()
+			close(stopped)
+		}()
+		return ctx
+	})
+	router := gin.Default()
+	router.POST("/sse", app.SSEHandler())
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	request, _ := http.NewRequestWithContext(ctx, "POST", server.URL+"/sse", bytes.NewBufferString(`{"query": "subscription { counter }"}`))
+	request.Header.Add("Content-Type", "application/json")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("Request failed. Err: %v", err)
+	}
+	defer response.Body.Close()
+
+	line, _ := bufio.NewReader(response.Body).ReadString('\n')
+	if !strings.HasPrefix(line, "data: ") {
+		t.Errorf("Event incorrect. Found %q", line)
+	}
+	cancel()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Errorf("Subscription not stopped after the client disconnected")
+	}
+}
@@ -0,0 +1,19 @@
+package graphqlgin
+
+import (
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Function to choose the response status code of an executed result
+type StatusCodeFn func(result *graphql.Result) int
+
+// A `StatusCodeFn` returning 400 for results with errors and no data, i.e. parse
+// and validation failures, and 200 otherwise, including partial data.
+func DefaultStatusCodeFn(result *graphql.Result) int {
+	if result.Data == nil && len(result.Errors) > 0 {
+		return http.StatusBadRequest
+	}
+	return http.StatusOK
+}
@@ -0,0 +1,49 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusCodeFnPOST(t *testing.T) {
+	app := New(schema)
+	app.StatusCodeFn = DefaultStatusCodeFn
+	router := setupRouter(app)
+
+	cases := []struct {
+		body   string
+		status int
+	}{
+		{`{"query": "{ hello }"}`, http.StatusOK},
+		{`{"query": "{ hello"}`, http.StatusBadRequest},
+		{`{"query": "{ unknown }"}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(tc.body))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != tc.status {
+			t.Errorf("Status code incorrect for %s. Found %d, expected %d", tc.body, recorder.Code, tc.status)
+		}
+	}
+}
+
+func TestStatusCodeFnUnsetPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ unknown }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusOK)
+	}
+}
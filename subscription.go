@@ -0,0 +1,405 @@
+package graphqlgin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// WebSocket subprotocol spoken by `SubscriptionHandler`
+const SubscriptionProtocol = "graphql-transport-ws"
+
+// WebSocket close code (Try Again Later) sent to subscription connections
+// rejected because the connection limit is reached
+const SubscriptionRetryCloseCode = 1013
+
+// Close codes of the graphql-transport-ws protocol
+const (
+	subscriptionInvalidMessage   = 4400
+	subscriptionUnauthorized     = 4401
+	subscriptionInitTimeout      = 4408
+	subscriptionDuplicateID      = 4409
+	subscriptionTooManyInitCalls = 4429
+)
+
+// Message types of the graphql-transport-ws protocol
+const (
+	subscriptionConnectionInit = "connection_init"
+	subscriptionConnectionAck  = "connection_ack"
+	subscriptionPing           = "ping"
+	subscriptionPong           = "pong"
+	subscriptionSubscribe      = "subscribe"
+	subscriptionNext           = "next"
+	subscriptionError          = "error"
+	subscriptionComplete       = "complete"
+)
+
+// Time a client has to send `connection_init` after connecting
+var subscriptionInitWait = 3 * time.Second
+
+// Message of the graphql-transport-ws protocol
+type subscriptionMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// Reserves a slot for a new subscription connection. Returns false when
+// `MaxSubscriptionConnections` connections are already open.
+func (app *GraphQLApp) acquireSubscriptionConnection() bool {
+	connections := atomic.AddInt32(&app.subscriptionConnections, 1)
+	if app.MaxSubscriptionConnections > 0 && int(connections) > app.MaxSubscriptionConnections {
+		atomic.AddInt32(&app.subscriptionConnections, -1)
+		return false
+	}
+	return true
+}
+
+// Frees the slot of a closed subscription connection.
+func (app *GraphQLApp) releaseSubscriptionConnection() {
+	atomic.AddInt32(&app.subscriptionConnections, -1)
+}
+
+// Returns a random delay within `SubscriptionStartupJitter` to wait before
+// starting a new subscription connection, spreading out reconnect storms.
+func (app *GraphQLApp) subscriptionStartupDelay() time.Duration {
+	if app.SubscriptionStartupJitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(app.SubscriptionStartupJitter)))
+}
+
+// Returns the channel closed by `CloseSubscriptions`.
+func (app *GraphQLApp) subscriptionsDone() chan struct{} {
+	app.subscriptionsMu.Lock()
+	defer app.subscriptionsMu.Unlock()
+	if app.subscriptionsClosed == nil {
+		app.subscriptionsClosed = make(chan struct{})
+	}
+	return app.subscriptionsClosed
+}
+
+// Closes all open subscription connections with the going away close code and
+// rejects new ones. Since `http.Server.Shutdown` does not track WebSocket
+// connections, register it with `http.Server.RegisterOnShutdown`.
+func (app *GraphQLApp) CloseSubscriptions() {
+	done := app.subscriptionsDone()
+	app.subscriptionsMu.Lock()
+	defer app.subscriptionsMu.Unlock()
+	select {
+	case <-done:
+	default:
+		close(done)
+	}
+}
+
+// Factory function to create a `gin.HandlerFunc` serving GraphQL subscriptions
+// over WebSocket with the graphql-transport-ws protocol.
+//
+// The context providers of the app, followed by `contextProviders`, are called
+// for each subscription, so resolvers see the same context values as for queries.
+func (app *GraphQLApp) SubscriptionHandler(contextProviders ...ContextProviderFn) gin.HandlerFunc {
+	providers := app.handlerProviders(contextProviders)
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{SubscriptionProtocol},
+	}
+
+	return func(c *gin.Context) {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// the upgrader has already replied with an error
+			return
+		}
+		defer conn.Close()
+
+		select {
+		case <-app.subscriptionsDone():
+			closeSubscriptionConn(conn, websocket.CloseGoingAway, "server shutting down")
+			return
+		default:
+		}
+		if !app.acquireSubscriptionConnection() {
+			closeSubscriptionConn(conn, SubscriptionRetryCloseCode, "too many connections")
+			return
+		}
+		defer app.releaseSubscriptionConnection()
+		if delay := app.subscriptionStartupDelay(); delay > 0 {
+			time.Sleep(delay)
+		}
+
+		session := &subscriptionSession{
+			app:           app,
+			c:             c,
+			conn:          conn,
+			providers:     providers,
+			subscriptions: map[string]context.CancelFunc{},
+		}
+		session.serve()
+	}
+}
+
+// Sends a close frame with `code` and `reason`.
+func closeSubscriptionConn(conn *websocket.Conn, code int, reason string) {
+	conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(time.Second),
+	)
+}
+
+// State of a single subscription connection
+type subscriptionSession struct {
+	app       *GraphQLApp
+	c         *gin.Context
+	conn      *websocket.Conn
+	providers []ContextProviderFn
+
+	// serializes writes to the connection
+	writeMu sync.Mutex
+
+	// serializes the setup of subscriptions, which share the `*gin.Context`
+	setupMu sync.Mutex
+
+	// cancel functions of the running subscriptions by id
+	mu            sync.Mutex
+	subscriptions map[string]context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// Writes a protocol message to the connection.
+func (s *subscriptionSession) write(message subscriptionMessage) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteJSON(message)
+}
+
+// Writes a protocol message with `payload` marshalled as JSON.
+func (s *subscriptionSession) writePayload(id string, messageType string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return s.write(subscriptionMessage{ID: id, Type: messageType, Payload: data})
+}
+
+// Sends a close frame, serialized with the other writes.
+func (s *subscriptionSession) close(code int, reason string) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	closeSubscriptionConn(s.conn, code, reason)
+}
+
+// Runs the protocol until the connection is closed by either side.
+func (s *subscriptionSession) serve() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		s.wg.Wait()
+	}()
+
+	messages := make(chan subscriptionMessage)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			var message subscriptionMessage
+			if err := s.conn.ReadJSON(&message); err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case messages <- message:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	initTimer := time.NewTimer(subscriptionInitWait)
+	defer initTimer.Stop()
+	initialized := false
+	for {
+		select {
+		case <-s.app.subscriptionsDone():
+			s.close(websocket.CloseGoingAway, "server shutting down")
+			return
+		case <-initTimer.C:
+			if !initialized {
+				s.close(subscriptionInitTimeout, "Connection initialisation timeout")
+				return
+			}
+		case err := <-readErr:
+			if _, ok := err.(*json.SyntaxError); ok {
+				s.close(subscriptionInvalidMessage, "Invalid message received")
+			}
+			return
+		case message := <-messages:
+			switch message.Type {
+			case subscriptionConnectionInit:
+				if initialized {
+					s.close(subscriptionTooManyInitCalls, "Too many initialisation requests")
+					return
+				}
+				initialized = true
+				if err := s.write(subscriptionMessage{Type: subscriptionConnectionAck}); err != nil {
+					return
+				}
+			case subscriptionPing:
+				if err := s.write(subscriptionMessage{Type: subscriptionPong}); err != nil {
+					return
+				}
+			case subscriptionPong:
+			case subscriptionSubscribe:
+				if !initialized {
+					s.close(subscriptionUnauthorized, "Unauthorized")
+					return
+				}
+				if !s.subscribe(ctx, message) {
+					return
+				}
+			case subscriptionComplete:
+				s.unsubscribe(message.ID)
+			default:
+				s.close(subscriptionInvalidMessage, fmt.Sprintf("Invalid message type %q", message.Type))
+				return
+			}
+		}
+	}
+}
+
+// Starts the subscription requested by a subscribe `message`. Returns false if
+// the connection was closed because of a protocol violation.
+func (s *subscriptionSession) subscribe(ctx context.Context, message subscriptionMessage) bool {
+	var params GraphQLRequestParams
+	if message.ID == "" || json.Unmarshal(message.Payload, &params) != nil {
+		s.close(subscriptionInvalidMessage, "Invalid subscribe message")
+		return false
+	}
+
+	s.mu.Lock()
+	if _, ok := s.subscriptions[message.ID]; ok {
+		s.mu.Unlock()
+		s.close(subscriptionDuplicateID, fmt.Sprintf("Subscriber for %s already exists", message.ID))
+		return false
+	}
+	subscriptionCtx, cancel := context.WithCancel(ctx)
+	s.subscriptions[message.ID] = cancel
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.unsubscribe(message.ID)
+		s.run(subscriptionCtx, message.ID, &params)
+	}()
+	return true
+}
+
+// Cancels the subscription with `id`, if it is running.
+func (s *subscriptionSession) unsubscribe(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cancel, ok := s.subscriptions[id]; ok {
+		cancel()
+		delete(s.subscriptions, id)
+	}
+}
+
+// Receives and discards the remaining `results` in the background, so the
+// source of a cancelled subscription can send them and stop.
+func drainResults(results chan *graphql.Result) {
+	go func() {
+		for range results {
+		}
+	}()
+}
+
+// Creates the resolver context and root object of a subscription, then loads
+// and checks its operation like the operations of `Handler`. Returns the error
+// reply rejecting the subscription, if any. Serialized with the other
+// subscriptions of the connection, since they share the `*gin.Context`.
+func (s *subscriptionSession) prepare(ctx context.Context, query *queryDocument) (context.Context, map[string]interface{}, map[string]interface{}) {
+	s.setupMu.Lock()
+	defer s.setupMu.Unlock()
+
+	resolverCtx, err := s.app.provideContext(s.c, ctx, s.providers)
+	if err != nil {
+		return ctx, nil, graphqlErrorReply("could not create resolver context", err)
+	}
+	resolverCtx, _, reply := s.app.prepareQuery(s.c, resolverCtx, query)
+	if reply == nil {
+		_, reply = s.app.checkQuery(s.c, resolverCtx, query)
+	}
+	if reply != nil {
+		return resolverCtx, nil, reply.(map[string]interface{})
+	}
+	return resolverCtx, s.app.rootObject(s.c), nil
+}
+
+// Executes a subscription, streaming its results until the source is exhausted
+// or the subscription is cancelled. The operation goes through the same query
+// loading and checks as the operations of `Handler`.
+func (s *subscriptionSession) run(ctx context.Context, id string, params *GraphQLRequestParams) {
+	query := &queryDocument{params: params}
+
+	// log the subscription once it ends
+	logCtx := ctx
+	var errs []gqlerrors.FormattedError
+	if s.app.Logger != nil || s.app.SummaryLogger != nil {
+		started := time.Now()
+		defer func() {
+			s.app.logSubscription(s.c, logCtx, started, query, errs)
+		}()
+	}
+
+	resolverCtx, rootObject, reply := s.prepare(ctx, query)
+	logCtx = resolverCtx
+	if reply != nil {
+		errs = replyErrors(reply)
+		s.writePayload(id, subscriptionError, reply["errors"])
+		return
+	}
+
+	results := graphql.Subscribe(graphql.Params{
+		Schema:         s.app.executableSchema(),
+		RequestString:  params.RequestString,
+		RootObject:     rootObject,
+		OperationName:  params.OperationName,
+		VariableValues: params.VariableValues,
+		Context:        resolverCtx,
+	})
+	// the source blocks sending results until it sees the cancellation
+	defer drainResults(results)
+	first := true
+	for {
+		select {
+		case <-ctx.Done():
+			// cancelled by the client or the connection closed
+			return
+		case result, ok := <-results:
+			if !ok {
+				s.write(subscriptionMessage{ID: id, Type: subscriptionComplete})
+				return
+			}
+			s.app.formatResultErrors(resolverCtx, params.RequestString, result)
+			errs = append(errs, result.Errors...)
+			if first && result.Data == nil && len(result.Errors) > 0 {
+				// the operation failed before the source stream was created
+				s.writePayload(id, subscriptionError, result.Errors)
+				return
+			}
+			first = false
+			if err := s.writePayload(id, subscriptionNext, result); err != nil {
+				return
+			}
+		}
+	}
+}
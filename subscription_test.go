@@ -0,0 +1,344 @@
+package graphqlgin
+
+import (
+	"context"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+)
+
+var subscriptionSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+		},
+	}),
+	Subscription: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"counter": &graphql.Field{
+				Type: graphql.String,
+				Args: graphql.FieldConfigArgument{
+					"to": &graphql.ArgumentConfig{Type: graphql.Int},
+				},
+				Subscribe: func(p graphql.ResolveParams) (interface{}, error) {
+					to, _ := p.Args["to"].(int)
+					events := make(chan interface{})
+					go func() {
+						defer close(events)
+						for i := 1; to == 0 || i <= to; i++ {
+							select {
+							case events <- i:
+							case <-p.Context.Done():
+								return
+							}
+						}
+					}()
+					return events, nil
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					prefix, _ := p.Context.Value("prefix").(string)
+					return prefix + strings.Repeat("*", p.Source.(int)), nil
+				},
+			},
+		},
+	}),
+})
+
+func prefixProvider(c *gin.Context, ctx context.Context) context.Context {
+	return context.WithValue(ctx, "prefix", c.Query("prefix"))
+}
+
+// Starts a server with the subscription handler of `app` mounted on `/ws`.
+func newSubscriptionServer(app *GraphQLApp) *httptest.Server {
+	router := gin.Default()
+	router.GET("/ws", app.SubscriptionHandler(prefixProvider))
+	return httptest.NewServer(router)
+}
+
+func dialSubscriptions(t *testing.T, server *httptest.Server, query string) *websocket.Conn {
+	dialer := websocket.Dialer{Subprotocols: []string{SubscriptionProtocol}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws"+query, nil)
+	if err != nil {
+		t.Fatalf("Dial failed. Err: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	return conn
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	var message map[string]interface{}
+	if err := conn.ReadJSON(&message); err != nil {
+		t.Fatalf("Read failed. Err: %v", err)
+	}
+	return message
+}
+
+func TestMaxSubscriptionConnections(t *testing.T) {
+	app := New(schema)
+	app.MaxSubscriptionConnections = 2
+
+	for i := 0; i < 2; i++ {
+		if !app.acquireSubscriptionConnection() {
+			t.Fatalf("Connection %d rejected below the cap", i)
+		}
+	}
+	if app.acquireSubscriptionConnection() {
+		t.Errorf("Connection accepted above the cap of %d", app.MaxSubscriptionConnections)
+	}
+
+	app.releaseSubscriptionConnection()
+	if !app.acquireSubscriptionConnection() {
+		t.Errorf("Connection rejected after a slot was released")
+	}
+}
+
+func TestSubscriptionStartupDelay(t *testing.T) {
+	app := New(schema)
+	if delay := app.subscriptionStartupDelay(); delay != 0 {
+		t.Errorf("Delay without jitter incorrect. Found %s, expected 0", delay)
+	}
+
+	app.SubscriptionStartupJitter = 50 * time.Millisecond
+	for i := 0; i < 10; i++ {
+		if delay := app.subscriptionStartupDelay(); delay < 0 || delay >= app.SubscriptionStartupJitter {
+			t.Errorf("Delay out of the jitter window. Found %s", delay)
+		}
+	}
+}
+
+func TestSubscriptionHandler(t *testing.T) {
+	app := New(subscriptionSchema)
+	server := newSubscriptionServer(app)
+	defer server.Close()
+
+	conn := dialSubscriptions(t, server, "?prefix=n")
+	defer conn.Close()
+
+	if conn.Subprotocol() != SubscriptionProtocol {
+		t.Errorf("Subprotocol incorrect. Found %s, expected %s", conn.Subprotocol(), SubscriptionProtocol)
+	}
+	conn.WriteJSON(map[string]interface{}{"type": "connection_init"})
+	if message := readMessage(t, conn); message["type"] != "connection_ack" {
+		t.Fatalf("Message type incorrect. Found %v, expected connection_ack", message["type"])
+	}
+
+	conn.WriteJSON(map[string]interface{}{"type": "ping"})
+	if message := readMessage(t, conn); message["type"] != "pong" {
+		t.Errorf("Message type incorrect. Found %v, expected pong", message["type"])
+	}
+
+	conn.WriteJSON(map[string]interface{}{
+		"id":      "1",
+		"type":    "subscribe",
+		"payload": map[string]interface{}{"query": "subscription { counter(to: 3) }"},
+	})
+	for _, expected := range []string{"n*", "n**", "n***"} {
+		message := readMessage(t, conn)
+		payload, _ := message["payload"].(map[string]interface{})
+		data, _ := payload["data"].(map[string]interface{})
+		if message["type"] != "next" || message["id"] != "1" || data["counter"] != expected {
+			t.Errorf("Next message incorrect. Found %v, expected counter %s", message, expected)
+		}
+	}
+	if message := readMessage(t, conn); message["type"] != "complete" || message["id"] != "1" {
+		t.Errorf("Complete message incorrect. Found %v", message)
+	}
+}
+
+func TestSubscriptionClientComplete(t *testing.T) {
+	app := New(subscriptionSchema)
+	server := newSubscriptionServer(app)
+	defer server.Close()
+
+	conn := dialSubscriptions(t, server, "")
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]interface{}{"type": "connection_init"})
+	readMessage(t, conn)
+	conn.WriteJSON(map[string]interface{}{
+		"id":      "1",
+		"type":    "subscribe",
+		"payload": map[string]interface{}{"query": "subscription { counter }"},
+	})
+	if message := readMessage(t, conn); message["type"] != "next" {
+		t.Fatalf("Message type incorrect. Found %v, expected next", message["type"])
+	}
+	conn.WriteJSON(map[string]interface{}{"id": "1", "type": "complete"})
+
+	// the subscription stops, the connection keeps serving
+	conn.WriteJSON(map[string]interface{}{"type": "ping"})
+	for {
+		message := readMessage(t, conn)
+		if message["type"] == "pong" {
+			break
+		}
+		if message["type"] != "next" {
+			t.Fatalf("Message type incorrect. Found %v, expected next or pong", message["type"])
+		}
+	}
+}
+
+func TestSubscriptionValidationError(t *testing.T) {
+	app := New(subscriptionSchema)
+	server := newSubscriptionServer(app)
+	defer server.Close()
+
+	conn := dialSubscriptions(t, server, "")
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]interface{}{"type": "connection_init"})
+	readMessage(t, conn)
+	conn.WriteJSON(map[string]interface{}{
+		"id":      "1",
+		"type":    "subscribe",
+		"payload": map[string]interface{}{"query": "subscription { unknown }"},
+	})
+	message := readMessage(t, conn)
+	if errors, _ := message["payload"].([]interface{}); message["type"] != "error" || len(errors) != 1 {
+		t.Errorf("Error message incorrect. Found %v", message)
+	}
+}
+
+func TestSubscriptionUnauthorized(t *testing.T) {
+	app := New(subscriptionSchema)
+	server := newSubscriptionServer(app)
+	defer server.Close()
+
+	conn := dialSubscriptions(t, server, "")
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]interface{}{
+		"id":      "1",
+		"type":    "subscribe",
+		"payload": map[string]interface{}{"query": "subscription { counter }"},
+	})
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, 4401) {
+		t.Errorf("Close error incorrect. Found %v, expected close code 4401", err)
+	}
+}
+
+func TestSubscriptionConnectionCap(t *testing.T) {
+	app := New(subscriptionSchema)
+	app.MaxSubscriptionConnections = 1
+	server := newSubscriptionServer(app)
+	defer server.Close()
+
+	conn := dialSubscriptions(t, server, "")
+	defer conn.Close()
+	conn.WriteJSON(map[string]interface{}{"type": "connection_init"})
+	readMessage(t, conn)
+
+	excess := dialSubscriptions(t, server, "")
+	defer excess.Close()
+	_, _, err := excess.ReadMessage()
+	if !websocket.IsCloseError(err, SubscriptionRetryCloseCode) {
+		t.Errorf("Close error incorrect. Found %v, expected close code %d", err, SubscriptionRetryCloseCode)
+	}
+}
+
+func TestCloseSubscriptions(t *testing.T) {
+	app := New(subscriptionSchema)
+	server := newSubscriptionServer(app)
+	defer server.Close()
+
+	conn := dialSubscriptions(t, server, "")
+	defer conn.Close()
+	conn.WriteJSON(map[string]interface{}{"type": "connection_init"})
+	readMessage(t, conn)
+
+	app.CloseSubscriptions()
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.CloseGoingAway) {
+		t.Errorf("Close error incorrect. Found %v, expected close code %d", err, websocket.CloseGoingAway)
+	}
+}
+
+func TestSubscriptionTrustedQueriesOnly(t *testing.T) {
+	app := New(subscriptionSchema)
+	app.TrustedQueriesOnly = true
+	app.TrustedQueries = map[string]string{"counter": "subscription { counter(to: 1) }"}
+	server := newSubscriptionServer(app)
+	defer server.Close()
+
+	conn := dialSubscriptions(t, server, "?prefix=n")
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]interface{}{"type": "connection_init"})
+	readMessage(t, conn)
+
+	// ad-hoc operations are rejected
+	conn.WriteJSON(map[string]interface{}{
+		"id":      "1",
+		"type":    "subscribe",
+		"payload": map[string]interface{}{"query": "subscription { counter(to: 1) }"},
+	})
+	message := readMessage(t, conn)
+	errs, _ := message["payload"].([]interface{})
+	if message["type"] != "error" || message["id"] != "1" || len(errs) != 1 {
+		t.Fatalf("Error message incorrect. Found %v", message)
+	}
+	if err, _ := errs[0].(map[string]interface{}); !strings.HasPrefix(err["message"].(string), "query not trusted") {
+		t.Errorf("Error incorrect. Found %v", err)
+	}
+
+	// trusted operations are run
+	conn.WriteJSON(map[string]interface{}{
+		"id":   "2",
+		"type": "subscribe",
+		"payload": map[string]interface{}{
+			"extensions": map[string]interface{}{"persistedQuery": map[string]interface{}{"sha256Hash": "counter"}},
+		},
+	})
+	message = readMessage(t, conn)
+	payload, _ := message["payload"].(map[string]interface{})
+	data, _ := payload["data"].(map[string]interface{})
+	if message["type"] != "next" || message["id"] != "2" || data["counter"] != "n*" {
+		t.Errorf("Next message incorrect. Found %v", message)
+	}
+}
+
+func TestSubscriptionCancelStopsSource(t *testing.T) {
+	app := New(subscriptionSchema)
+	server := newSubscriptionServer(app)
+	baseline := runtime.NumGoroutine()
+
+	conn := dialSubscriptions(t, server, "")
+	conn.WriteJSON(map[string]interface{}{"type": "connection_init"})
+	readMessage(t, conn)
+	for _, id := range []string{"1", "2", "3"} {
+		conn.WriteJSON(map[string]interface{}{
+			"id":      id,
+			"type":    "subscribe",
+			"payload": map[string]interface{}{"query": "subscription { counter }"},
+		})
+	}
+	// the executors are blocked sending their next results when cancelled
+	for i := 0; i < 3; i++ {
+		if message := readMessage(t, conn); message["type"] != "next" {
+			t.Fatalf("Message type incorrect. Found %v, expected next", message["type"])
+		}
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		conn.WriteJSON(map[string]interface{}{"id": id, "type": "complete"})
+	}
+	conn.Close()
+	server.Close()
+
+	// the executors of the cancelled subscriptions stop with the connection
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if goroutines := runtime.NumGoroutine(); goroutines > baseline {
+		t.Errorf("Goroutines leaked. Found %d, expected at most %d", goroutines, baseline)
+	}
+}
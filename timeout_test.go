@@ -0,0 +1,125 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+)
+
+var slowSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+			"slow": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					select {
+					case <-p.Context.Done():
+						return nil, p.Context.Err()
+					case <-time.After(time.Second):
+						return "done", nil
+					}
+				},
+			},
+		},
+	}),
+})
+
+func TestRequestTimeoutPOST(t *testing.T) {
+	app := New(slowSchema)
+	app.RequestTimeout = 20 * time.Millisecond
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ slow }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusGatewayTimeout {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusGatewayTimeout)
+	}
+	expected := `{"errors":[{"message":"request timed out (execution exceeded the timeout of 20ms)"}]}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestRequestTimeoutNotExpiredPOST(t *testing.T) {
+	app := New(slowSchema)
+	app.RequestTimeout = time.Second
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if body := recorder.Body.String(); body != `{"data":{"hello":"world"}}` {
+		t.Errorf("Response incorrect. Found %s", body)
+	}
+}
+
+func TestClientDisconnectPOST(t *testing.T) {
+	type providerKey struct{}
+	resolverErrs := make(chan error, 1)
+	providerValues := make(chan interface{}, 1)
+	disconnectSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"slow": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						providerValues <- p.Context.Value(providerKey{})
+						select {
+						case <-p.Context.Done():
+							resolverErrs <- p.Context.Err()
+							return nil, p.Context.Err()
+						case <-time.After(time.Second):
+							return "done", nil
+						}
+					},
+				},
+			},
+		}),
+	})
+	app := New(disconnectSchema)
+	app.Use(func(c *gin.Context, ctx context.Context) context.Context {
+		return context.WithValue(ctx, providerKey{}, "wrapped")
+	})
+	router := setupRouter(app)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ slow }"}`))
+	request = request.WithContext(ctx)
+	request.Header.Add("Content-Type", "application/json")
+
+	started := time.Now()
+	router.ServeHTTP(recorder, request)
+
+	if elapsed := time.Since(started); elapsed >= time.Second {
+		t.Errorf("Request not cancelled. Found %s, expected less than %s", elapsed, time.Second)
+	}
+	if value := <-providerValues; value != "wrapped" {
+		t.Errorf("Provider value incorrect. Found %v, expected %v", value, "wrapped")
+	}
+	select {
+	case err := <-resolverErrs:
+		if err != context.Canceled {
+			t.Errorf("Resolver context error incorrect. Found %v, expected %v", err, context.Canceled)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Resolver context not cancelled")
+	}
+}
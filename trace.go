@@ -0,0 +1,63 @@
+package graphqlgin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// W3C trace context header
+const TraceParentHeader = "traceparent"
+
+// Key for setting the W3C trace parent of the current request to the context
+const traceParentKey contextKey = "TraceParent"
+
+// Format of a W3C trace parent: version-traceid-parentid-flags
+var traceParentPattern = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// Checks if `traceParent` is a well formed W3C trace parent with non-zero ids.
+func validTraceParent(traceParent string) bool {
+	if !traceParentPattern.MatchString(traceParent) || traceParent[:2] == "ff" {
+		return false
+	}
+	return traceParent[3:35] != "00000000000000000000000000000000" &&
+		traceParent[36:52] != "0000000000000000"
+}
+
+// Generates a new sampled W3C trace parent with random ids.
+func newTraceParent() string {
+	ids := make([]byte, 24)
+	rand.Read(ids)
+	return "00-" + hex.EncodeToString(ids[:16]) + "-" + hex.EncodeToString(ids[16:]) + "-01"
+}
+
+// A `ContextProviderFn` that adds the W3C trace parent of the request to the
+// context passed down to resolver functions, and echoes it in the response headers.
+// A new trace parent is generated when the request lacks a valid one.
+func TraceParentProvider(c *gin.Context, ctx context.Context) context.Context {
+	traceParent := c.GetHeader(TraceParentHeader)
+	if !validTraceParent(traceParent) {
+		traceParent = newTraceParent()
+	}
+	c.Header(TraceParentHeader, traceParent)
+	return WithTraceParent(ctx, traceParent)
+}
+
+// Returns a copy of `ctx` carrying the W3C trace parent `traceParent`, i.e. to
+// unit test resolvers calling `GetTraceParent`.
+func WithTraceParent(ctx context.Context, traceParent string) context.Context {
+	return context.WithValue(
+		ctx,
+		traceParentKey,
+		traceParent,
+	)
+}
+
+// Extracts and returns the W3C trace parent from the context `ctx`.
+func GetTraceParent(ctx context.Context) string {
+	traceParent, _ := ctx.Value(traceParentKey).(string)
+	return traceParent
+}
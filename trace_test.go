@@ -0,0 +1,76 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+)
+
+var traceSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"traceParent": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return GetTraceParent(p.Context), nil
+				},
+			},
+		},
+	}),
+})
+
+func TestTraceParentProviderPOST(t *testing.T) {
+	app := New(traceSchema, TraceParentProvider)
+	router := setupRouter(app)
+
+	traceParent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ traceParent }"}`))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add(TraceParentHeader, traceParent)
+
+	router.ServeHTTP(recorder, request)
+
+	if body := recorder.Body.String(); body != `{"data":{"traceParent":"`+traceParent+`"}}` {
+		t.Errorf("Trace parent not propagated to resolver. Body: %s", body)
+	}
+	if header := recorder.Header().Get(TraceParentHeader); header != traceParent {
+		t.Errorf("Trace parent not echoed. Found %s, expected %s", header, traceParent)
+	}
+}
+
+func TestTraceParentProviderGeneratedPOST(t *testing.T) {
+	app := New(traceSchema, TraceParentProvider)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ traceParent }"}`))
+	request.Header.Add("Content-Type", "application/json")
+	request.Header.Add(TraceParentHeader, "garbage")
+
+	router.ServeHTTP(recorder, request)
+
+	header := recorder.Header().Get(TraceParentHeader)
+	if !validTraceParent(header) {
+		t.Errorf("Generated trace parent is invalid. Found %s", header)
+	}
+	if body := recorder.Body.String(); body != `{"data":{"traceParent":"`+header+`"}}` {
+		t.Errorf("Generated trace parent not propagated to resolver. Body: %s", body)
+	}
+}
+
+func TestWithTraceParent(t *testing.T) {
+	traceParent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	if found := GetTraceParent(WithTraceParent(context.Background(), traceParent)); found != traceParent {
+		t.Errorf("Trace parent incorrect. Found %s, expected %s", found, traceParent)
+	}
+	// untyped keys of other packages don't collide with the trace parent
+	if found := GetTraceParent(context.WithValue(context.Background(), "TraceParent", traceParent)); found != "" {
+		t.Errorf("Trace parent read from an untyped key. Found %s", found)
+	}
+}
@@ -0,0 +1,96 @@
+package graphqlgin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/graphql-go/graphql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attribute keys of the spans created with `GraphQLApp.Tracer`
+const (
+	TracingOperationNameKey = attribute.Key("graphql.operation.name")
+	TracingOperationTypeKey = attribute.Key("graphql.operation.type")
+	TracingVariablesKey     = attribute.Key("graphql.variables.count")
+	TracingFieldPathKey     = attribute.Key("graphql.field.path")
+	TracingErrorMessageKey  = attribute.Key("graphql.error.message")
+	TracingErrorPathKey     = attribute.Key("graphql.error.path")
+)
+
+// Name of the span events recording the errors of a result
+const tracingErrorEvent = "graphql.error"
+
+// Starts the span of the operation requested by `query`, returning the context
+// carrying the span.
+func (app *GraphQLApp) startOperationSpan(ctx context.Context, query *queryDocument) (context.Context, trace.Span) {
+	name := requestOperationName(query)
+	operationType := requestOperationType(query)
+	spanName := "GraphQL Operation"
+	if operationType != "" {
+		spanName = operationType
+		if name != "" {
+			spanName += " " + name
+		}
+	}
+	return app.Tracer.Start(
+		ctx,
+		spanName,
+		trace.WithAttributes(
+			TracingOperationNameKey.String(name),
+			TracingOperationTypeKey.String(operationType),
+			TracingVariablesKey.Int(len(query.params.VariableValues)),
+		),
+	)
+}
+
+// Records the errors of `result` as events of `span` and ends it.
+func endOperationSpan(span trace.Span, result *graphql.Result) {
+	for _, err := range result.Errors {
+		path := make([]string, 0, len(err.Path))
+		for _, key := range err.Path {
+			path = append(path, fmt.Sprint(key))
+		}
+		span.AddEvent(tracingErrorEvent, trace.WithAttributes(
+			TracingErrorMessageKey.String(err.Message),
+			TracingErrorPathKey.StringSlice(path),
+		))
+	}
+	if len(result.Errors) > 0 {
+		span.SetStatus(codes.Error, result.Errors[0].Message)
+	}
+	span.End()
+}
+
+// Starts a child span of the operation span for each resolved field.
+func (app *GraphQLApp) traceField(next graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		if app.Tracer == nil || !trace.SpanFromContext(p.Context).IsRecording() {
+			return next(p)
+		}
+		ctx, span := app.Tracer.Start(
+			p.Context,
+			p.Info.ParentType.Name()+"."+p.Info.FieldName,
+			trace.WithAttributes(TracingFieldPathKey.String(formatPath(p.Info.Path))),
+		)
+		defer span.End()
+		p.Context = ctx
+		result, err := next(p)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return result, err
+	}
+}
+
+// Creates a child span of the operation span for every resolved field. Spans
+// are only created when `Tracer` is set. As field spans can be expensive, this
+// is not enabled by default. Like `WrapResolvers`, it must be called before
+// serving any request; calling it again has no effect.
+func (app *GraphQLApp) TraceFieldResolvers() error {
+	app.traceFieldResolvers = true
+	return app.wrapSchemaResolvers()
+}
@@ -0,0 +1,170 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type recordedSpan struct {
+	trace.Span
+	name       string
+	parent     *recordedSpan
+	attributes map[attribute.Key]attribute.Value
+	events     []string
+	status     codes.Code
+	ended      bool
+}
+
+func (s *recordedSpan) IsRecording() bool { return true }
+
+func (s *recordedSpan) AddEvent(name string, options ...trace.EventOption) {
+	s.events = append(s.events, name)
+}
+
+func (s *recordedSpan) RecordError(err error, options ...trace.EventOption) {
+	s.events = append(s.events, "exception")
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, description string) { s.status = code }
+
+func (s *recordedSpan) End(options ...trace.SpanEndOption) { s.ended = true }
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (tr *recordingTracer) Start(ctx context.Context, name string, options ...trace.SpanStartOption) (context.Context, trace.Span) {
+	config := trace.NewSpanStartConfig(options...)
+	span := &recordedSpan{
+		Span:       trace.SpanFromContext(context.Background()),
+		name:       name,
+		attributes: map[attribute.Key]attribute.Value{},
+	}
+	span.parent, _ = trace.SpanFromContext(ctx).(*recordedSpan)
+	for _, kv := range config.Attributes() {
+		span.attributes[kv.Key] = kv.Value
+	}
+	tr.mu.Lock()
+	tr.spans = append(tr.spans, span)
+	tr.mu.Unlock()
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+func (tr *recordingTracer) span(name string) *recordedSpan {
+	for _, span := range tr.spans {
+		if span.name == name {
+			return span
+		}
+	}
+	return nil
+}
+
+func newTracingSchema() graphql.Schema {
+	s, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": helloQuery,
+				"traced": &graphql.Field{
+					Type: graphql.Boolean,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						_, ok := trace.SpanFromContext(p.Context).(*recordedSpan)
+						return ok, nil
+					},
+				},
+				"broken": &graphql.Field{
+					Type: graphql.String,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						return nil, errors.New("broken field")
+					},
+				},
+			},
+		}),
+	})
+	return s
+}
+
+func TestTracerPOST(t *testing.T) {
+	tracer := &recordingTracer{}
+	app := New(newTracingSchema())
+	app.Tracer = tracer
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "query check($a: Boolean!) { traced @include(if: $a) broken }", "variables": {"a": true}}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if body := recorder.Body.String(); !strings.Contains(body, `"traced":true`) {
+		t.Errorf("Span not propagated to the resolver. Body: %s", body)
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Spans count incorrect. Found %d, expected %d", len(tracer.spans), 1)
+	}
+	span := tracer.spans[0]
+	if span.name != "query check" || !span.ended {
+		t.Errorf("Span incorrect. Found %s (ended: %v)", span.name, span.ended)
+	}
+	if span.attributes[TracingOperationNameKey].AsString() != "check" ||
+		span.attributes[TracingOperationTypeKey].AsString() != "query" ||
+		span.attributes[TracingVariablesKey].AsInt64() != 1 {
+		t.Errorf("Span attributes incorrect. Found %v", span.attributes)
+	}
+	if len(span.events) != 1 || span.events[0] != tracingErrorEvent || span.status != codes.Error {
+		t.Errorf("Span errors incorrect. Found %v (status %v)", span.events, span.status)
+	}
+}
+
+func TestTraceFieldResolversPOST(t *testing.T) {
+	tracer := &recordingTracer{}
+	app := New(newTracingSchema())
+	app.Tracer = tracer
+	app.TraceFieldResolvers()
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ hello broken }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	operation := tracer.span("query")
+	for _, name := range []string{"Query.hello", "Query.broken"} {
+		span := tracer.span(name)
+		if span == nil || span.parent != operation || !span.ended {
+			t.Errorf("Field span %s incorrect. Found %+v", name, span)
+		}
+	}
+	if broken := tracer.span("Query.broken"); broken == nil || broken.status != codes.Error {
+		t.Errorf("Field span error not recorded")
+	}
+}
+
+func TestNoTracerPOST(t *testing.T) {
+	app := New(newTracingSchema())
+	app.TraceFieldResolvers()
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"query": "{ traced }"}`))
+	request.Header.Add("Content-Type", "application/json")
+
+	router.ServeHTTP(recorder, request)
+
+	if body := recorder.Body.String(); body != `{"data":{"traced":false}}` {
+		t.Errorf("Response incorrect. Found %s", body)
+	}
+}
@@ -0,0 +1,50 @@
+package graphqlgin
+
+import (
+	"fmt"
+)
+
+// Returns the hash of the persisted query extension of the request, if any.
+func requestQueryHash(params *GraphQLRequestParams) string {
+	persistedQuery, _ := params.Extensions[persistedQueryExtension].(map[string]interface{})
+	hash, _ := persistedQuery[persistedQueryHashKey].(string)
+	return hash
+}
+
+// Replaces the query of the request with the trusted query of its hash,
+// returning whether one was found. With `TrustedQueriesOnly` the query text of
+// the request is ignored, and requests without a known hash are rejected.
+func (app *GraphQLApp) loadTrustedQuery(params *GraphQLRequestParams) (bool, error) {
+	hash := requestQueryHash(params)
+	if app.TrustedQueriesOnly || params.RequestString == "" {
+		if query, ok := app.TrustedQueries[hash]; ok && hash != "" {
+			params.RequestString = query
+			return true, nil
+		}
+	}
+	if !app.TrustedQueriesOnly {
+		return false, nil
+	}
+	if hash == "" {
+		return false, fmt.Errorf("operations must be sent by the hash of a trusted query")
+	}
+	return false, fmt.Errorf("unknown trusted query hash %q", hash)
+}
+
+// Loads the trusted query, or else the automatic persisted query, of `query`
+// once, returning the error reply rejecting the request, if any.
+func (app *GraphQLApp) loadQuery(query *queryDocument) map[string]interface{} {
+	if query.loaded {
+		return query.loadReply
+	}
+	query.loaded = true
+	trusted, err := app.loadTrustedQuery(query.params)
+	if err != nil {
+		query.loadReply = graphqlErrorReply("query not trusted", err)
+	} else if !trusted {
+		if err := app.loadPersistedQuery(query.params); err != nil {
+			query.loadReply = err.reply()
+		}
+	}
+	return query.loadReply
+}
@@ -0,0 +1,71 @@
+package graphqlgin
+
+import (
+	"testing"
+)
+
+func TestTrustedQueriesOnlyPOST(t *testing.T) {
+	app := New(schema)
+	app.TrustedQueries = map[string]string{"abc": "{ hello }"}
+	app.TrustedQueriesOnly = true
+	router := setupRouter(app)
+
+	for _, test := range []struct {
+		query    string
+		hash     string
+		expected string
+	}{
+		{"", "abc", `{"data":{"hello":"world"}}`},
+		{"{ double(value: 2) }", "abc", `{"data":{"hello":"world"}}`},
+		{"{ hello }", "def", `{"errors":[{"message":"query not trusted (unknown trusted query hash \"def\")"}]}`},
+		{"{ hello }", "", `{"errors":[{"message":"query not trusted (operations must be sent by the hash of a trusted query)"}]}`},
+	} {
+		if body := persistedQueryRequest(router, test.query, test.hash); body != test.expected {
+			t.Errorf("Response of query %q with hash %q incorrect. Found %s, expected %s", test.query, test.hash, body, test.expected)
+		}
+	}
+}
+
+func TestTrustedQueriesPOST(t *testing.T) {
+	app := New(schema)
+	app.TrustedQueries = map[string]string{"abc": "{ hello }"}
+	router := setupRouter(app)
+
+	for _, test := range []struct {
+		query    string
+		hash     string
+		expected string
+	}{
+		{"", "abc", `{"data":{"hello":"world"}}`},
+		{"{ double(value: 2) }", "abc", `{"data":{"double":4}}`},
+		{"{ double(value: 2) }", "def", `{"data":{"double":4}}`},
+	} {
+		if body := persistedQueryRequest(router, test.query, test.hash); body != test.expected {
+			t.Errorf("Response of query %q with hash %q incorrect. Found %s, expected %s", test.query, test.hash, body, test.expected)
+		}
+	}
+}
+
+func TestTrustedQueriesOnlyConcurrentPOST(t *testing.T) {
+	for _, queryCacheSize := range []int{0, 10} {
+		app := New(schema)
+		app.TrustedQueries = map[string]string{"abc": "{ hello }"}
+		app.TrustedQueriesOnly = true
+		app.ConcurrentContextProviders = true
+		app.QueryCacheSize = queryCacheSize
+		router := setupRouter(app)
+
+		for _, test := range []struct {
+			query    string
+			hash     string
+			expected string
+		}{
+			{"{ double(value: 2) }", "abc", `{"data":{"hello":"world"}}`},
+			{"{ double(value: 2) }", "def", `{"errors":[{"message":"query not trusted (unknown trusted query hash \"def\")"}]}`},
+		} {
+			if body := persistedQueryRequest(router, test.query, test.hash); body != test.expected {
+				t.Errorf("Response of query %q with hash %q incorrect. Found %s, expected %s", test.query, test.hash, body, test.expected)
+			}
+		}
+	}
+}
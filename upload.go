@@ -0,0 +1,323 @@
+package graphqlgin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Categories of upload errors
+const (
+	// The client sent an invalid upload request, retrying won't help
+	UploadErrorClient = "client"
+	// The server failed to process a valid upload, the request may be retried
+	UploadErrorServer = "server"
+)
+
+// Storage backend for uploaded files
+type UploadStore interface {
+	// Stores the uploaded file and returns the value to be passed to the resolvers
+	Put(ctx context.Context, file *multipart.FileHeader) (interface{}, error)
+}
+
+// Error of processing a multipart upload request
+type UploadError struct {
+	// Either `UploadErrorClient` or `UploadErrorServer`
+	Category string
+	Message  string
+	Err      error
+	// Response status code used when `UploadErrorStatus` is set. When zero, it
+	// is derived from the category.
+	Status int
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("%s (%s)", e.Message, e.Err)
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+// Returns the error extensions describing the error category.
+func (e *UploadError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"category": e.Category,
+	}
+}
+
+// Shorthand function to construct a client fault upload error
+func uploadClientError(message string, err error) *UploadError {
+	return &UploadError{Category: UploadErrorClient, Message: message, Err: err}
+}
+
+// Shorthand function to construct a server fault upload error
+func uploadServerError(message string, err error) *UploadError {
+	return &UploadError{Category: UploadErrorServer, Message: message, Err: err}
+}
+
+// Returns the response status code for an upload error.
+func (app *GraphQLApp) uploadErrorStatus(err *UploadError) int {
+	if !app.UploadErrorStatus {
+		return http.StatusOK
+	}
+	if err.Status != 0 {
+		return err.Status
+	}
+	if err.Category == UploadErrorServer {
+		return http.StatusInternalServerError
+	}
+	return http.StatusBadRequest
+}
+
+// Checks the size of an uploaded file and the running total of all uploaded
+// files against the configured limits.
+func (app *GraphQLApp) checkUploadSize(file *multipart.FileHeader, total int64) *UploadError {
+	var err error
+	if app.MaxUploadSize > 0 && file.Size > app.MaxUploadSize {
+		err = fmt.Errorf("file %q of %d bytes exceeds the limit of %d bytes", file.Filename, file.Size, app.MaxUploadSize)
+	} else if app.MaxTotalUploadSize > 0 && total > app.MaxTotalUploadSize {
+		err = fmt.Errorf("uploaded files of %d bytes exceed the total limit of %d bytes", total, app.MaxTotalUploadSize)
+	}
+	if err != nil {
+		return &UploadError{
+			Category: UploadErrorClient,
+			Message:  "upload too large",
+			Err:      err,
+			Status:   http.StatusRequestEntityTooLarge,
+		}
+	}
+	return nil
+}
+
+// Parses the operations and map fields of a multipart request, as described by
+// the GraphQL multipart request specification, and injects the form values and
+// uploaded files into the request variables.
+func (app *GraphQLApp) processUploads(c *gin.Context, graphqlRequest *GraphQLRequest) *UploadError {
+	// unmarshal graphql operations
+	var graphqlOperations GraphQLRequestParams
+	if err := json.Unmarshal([]byte(graphqlRequest.OperationsString), &graphqlOperations); err != nil {
+		return uploadClientError("invalid operations string", err)
+	}
+
+	// unmarshal upload/variable map
+	variableMap := map[string][]string{}
+	if err := json.Unmarshal([]byte(graphqlRequest.MapString), &variableMap); err != nil {
+		return uploadClientError("invalid map string", err)
+	}
+
+	// collect form data from variable map
+	uploads := map[*multipart.FileHeader][]string{}
+	// values of plain variables keyed by their form key, as different keys may
+	// carry the same value
+	formValues := map[string]string{}
+	var totalUploadSize int64
+	for key, path := range variableMap {
+		if value, ok := c.GetPostForm(key); ok {
+			// this is a plain variable, not a file upload
+			formValues[key] = value
+		} else if fileHeader, err := c.FormFile(key); err == http.ErrMissingFile {
+			// every map key must reference a part of the request
+			return uploadClientError(
+				"missing multipart part",
+				fmt.Errorf("map key %q matches no form field or file", key),
+			)
+		} else if err != nil {
+			// the form was parsed during binding, so the client is not at fault
+			return uploadServerError("invalid file upload", err)
+		} else if fileHeader != nil {
+			// enforce the upload size limits before using the file
+			totalUploadSize += fileHeader.Size
+			if err := app.checkUploadSize(fileHeader, totalUploadSize); err != nil {
+				return err
+			}
+			if err := app.checkUploadType(fileHeader); err != nil {
+				return err
+			}
+			// we found a file upload, collect the header
+			uploads[fileHeader] = path
+		}
+	}
+
+	// update graphql request data
+	graphqlRequest.RequestString = graphqlOperations.RequestString
+	graphqlRequest.OperationName = graphqlOperations.OperationName
+	graphqlRequest.VariableValues = graphqlOperations.VariableValues
+	if graphqlRequest.VariableValues == nil {
+		graphqlRequest.VariableValues = map[string]interface{}{}
+	}
+
+	// set found form values to request variable values
+	for key, value := range formValues {
+		for _, path := range variableMap[key] {
+			if err := set(value, graphqlRequest.VariableValues, path, app.GrowUploadLists); err != nil {
+				return setUploadError(err)
+			}
+		}
+	}
+
+	// set found form file uploads to request variable values
+	return app.injectUploads(c, uploads, graphqlRequest.VariableValues)
+}
+
+// Converts an error of `set` into a client fault upload error.
+func setUploadError(err error) *UploadError {
+	var uploadErr *UploadError
+	if errors.As(err, &uploadErr) {
+		return uploadErr
+	}
+	return uploadClientError("could not set variable", err)
+}
+
+// Returns the SHA-256 hex digest of the content of an uploaded file.
+func uploadDigest(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Stores an uploaded file with the `UploadStore`, retrying failed attempts up to
+// `UploadStoreRetries` times. The wait between attempts starts at
+// `UploadStoreRetryBackoff` and doubles after each attempt.
+func (app *GraphQLApp) putUpload(ctx context.Context, file *multipart.FileHeader) (interface{}, error) {
+	backoff := app.UploadStoreRetryBackoff
+	for attempt := 0; ; attempt++ {
+		// each attempt opens the file anew, so it is read from the start
+		stored, err := app.UploadStore.Put(ctx, file)
+		if err == nil || attempt >= app.UploadStoreRetries {
+			return stored, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Key for keeping the uploaded files of the request in the `*gin.Context`
+const uploadedFilesKey = "graphqlgin.uploadedFiles"
+
+// Key for passing the uploaded files of the request to the resolvers
+const uploadedFilesContextKey contextKey = "UploadedFiles"
+
+// Returns the files uploaded with the request, ordered by their variable paths.
+// The returned slice is shared by all resolvers of the request and must not be
+// modified.
+func GetUploadedFiles(ctx context.Context) []*multipart.FileHeader {
+	files, _ := ctx.Value(uploadedFilesContextKey).([]*multipart.FileHeader)
+	return files
+}
+
+// Value returned by an `UploadStore`, marked so the `Upload` scalar accepts it.
+type storedUpload struct {
+	value interface{}
+}
+
+// Sets each uploaded file, or its stored value when an `UploadStore` is
+// configured, or its `*UploadedFile` with `UploadToDisk`, to its variable
+// paths. With `DeduplicateUploads`, files of identical content share the value
+// of the first one.
+func (app *GraphQLApp) injectUploads(c *gin.Context, uploads map[*multipart.FileHeader][]string, variables map[string]interface{}) *UploadError {
+	// keep the files for `GetUploadedFiles`
+	files := make([]*multipart.FileHeader, 0, len(uploads))
+	for file := range uploads {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return strings.Join(uploads[files[i]], ",") < strings.Join(uploads[files[j]], ",")
+	})
+	c.Set(uploadedFilesKey, files)
+
+	// values of already injected files keyed by their content hash
+	injected := map[string]interface{}{}
+	for file, paths := range uploads {
+		var value interface{} = file
+		digest := ""
+		if app.DeduplicateUploads {
+			var err error
+			if digest, err = uploadDigest(file); err != nil {
+				return uploadServerError("could not read file upload", err)
+			}
+		}
+		if stored, ok := injected[digest]; ok && digest != "" {
+			value = stored
+		} else if app.UploadStore != nil {
+			stored, err := app.putUpload(c.Request.Context(), file)
+			if err != nil {
+				return uploadServerError("could not store file upload", err)
+			}
+			value = storedUpload{stored}
+		} else if app.UploadToDisk {
+			uploaded, err := app.writeUploadToDisk(c, file)
+			if err != nil {
+				return uploadServerError("could not write file upload to disk", err)
+			}
+			value = uploaded
+		}
+		if digest != "" {
+			injected[digest] = value
+		}
+		for _, path := range paths {
+			if err := set(value, variables, path, app.GrowUploadLists); err != nil {
+				return setUploadError(err)
+			}
+		}
+	}
+	return nil
+}
+
+// Injects the files of a multipart request sent without the operations and map
+// fields into the request variables. The query and variables are bound from the
+// `query`, `operationName` and `variables` fields, while each file is set to the
+// variable named by its form key, which may also be a full `variables.` path.
+func (app *GraphQLApp) processUploadsWithoutMap(c *gin.Context, graphqlRequest *GraphQLRequest) *UploadError {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return uploadClientError("invalid multipart form", err)
+	}
+	if graphqlRequest.VariableValues == nil {
+		graphqlRequest.VariableValues = map[string]interface{}{}
+	}
+
+	uploads := map[*multipart.FileHeader][]string{}
+	var totalUploadSize int64
+	for key, fileHeaders := range form.File {
+		if len(fileHeaders) == 0 {
+			continue
+		}
+		fileHeader := fileHeaders[0]
+		totalUploadSize += fileHeader.Size
+		if err := app.checkUploadSize(fileHeader, totalUploadSize); err != nil {
+			return err
+		}
+		if err := app.checkUploadType(fileHeader); err != nil {
+			return err
+		}
+		path := key
+		if !strings.HasPrefix(path, "variables.") {
+			path = "variables." + path
+		}
+		uploads[fileHeader] = []string{path}
+	}
+	return app.injectUploads(c, uploads, graphqlRequest.VariableValues)
+}
@@ -0,0 +1,575 @@
+package graphqlgin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// Builds a multipart upload request, `files` maps form keys to file names and contents.
+func newUploadRequest(operations string, fileMap string, files map[string][2]string) *http.Request {
+	buff := bytes.NewBuffer(nil)
+	form := multipart.NewWriter(buff)
+	form.WriteField("operations", operations)
+	form.WriteField("map", fileMap)
+	for key, file := range files {
+		w, _ := form.CreateFormFile(key, file[0])
+		w.Write([]byte(file[1]))
+	}
+	form.Close()
+
+	request, _ := http.NewRequest("POST", "/", buff)
+	request.Header.Add("Content-Type", form.FormDataContentType())
+	return request
+}
+
+type uploadErrorResponse struct {
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+type failingStore struct{}
+
+func (failingStore) Put(ctx context.Context, file *multipart.FileHeader) (interface{}, error) {
+	return nil, errors.New("storage backend unavailable")
+}
+
+func TestUploadClientErrorPOST(t *testing.T) {
+	app := New(schema)
+	app.UploadErrorStatus = true
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+		`{"missing": ["variables.file"]}`,
+		map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusBadRequest {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusBadRequest)
+	}
+	var res uploadErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Extensions["category"] != UploadErrorClient {
+		t.Errorf("Error category incorrect. Body: %s", recorder.Body.String())
+	}
+}
+
+func TestUploadWithoutVariablesPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { filename size } }"}`,
+		`{"file": ["variables.file"]}`,
+		map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"singleUpload":{"filename":"hello.txt","size":12}}}`
+	if recorder.Code != http.StatusOK || recorder.Body.String() != expected {
+		t.Errorf("Response incorrect. Found %d %s, expected %s", recorder.Code, recorder.Body.String(), expected)
+	}
+}
+
+func TestUploadMissingMapKeyPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+		`{"missing": ["variables.file"]}`,
+		map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	var res uploadErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	expected := `missing multipart part (map key "missing" matches no form field or file)`
+	if len(res.Errors) != 1 || res.Errors[0].Message != expected {
+		t.Errorf("Error incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+}
+
+func TestUploadServerErrorPOST(t *testing.T) {
+	app := New(schema)
+	app.UploadErrorStatus = true
+	app.UploadStore = failingStore{}
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+		`{"file": ["variables.file"]}`,
+		map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusInternalServerError {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusInternalServerError)
+	}
+	var res uploadErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Extensions["category"] != UploadErrorServer {
+		t.Errorf("Error category incorrect. Body: %s", recorder.Body.String())
+	}
+}
+
+func TestUploadErrorDefaultStatusPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+		`not a map`,
+		nil,
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusOK)
+	}
+	var res uploadErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	if len(res.Errors) != 1 || res.Errors[0].Extensions["category"] != UploadErrorClient {
+		t.Errorf("Error category incorrect. Body: %s", recorder.Body.String())
+	}
+}
+
+func TestMaxUploadSizePOST(t *testing.T) {
+	app := New(schema)
+	app.MaxUploadSize = 8
+	app.UploadErrorStatus = true
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+		`{"file": ["variables.file"]}`,
+		map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusRequestEntityTooLarge)
+	}
+	var res uploadErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Errorf("Response unmarshal failed. Err: %v", err)
+	}
+	if len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "hello.txt") {
+		t.Errorf("Error does not name the file. Body: %s", recorder.Body.String())
+	}
+}
+
+func TestMaxTotalUploadSizePOST(t *testing.T) {
+	app := New(schema)
+	app.MaxUploadSize = 12
+	app.MaxTotalUploadSize = 16
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation ($files: [Upload!]!) { multiUpload(files: $files) { size } }", "variables": {"files": [null, null]}}`,
+		`{"0": ["variables.files.0"], "1": ["variables.files.1"]}`,
+		map[string][2]string{
+			"0": {"hello.txt", "Hello, World"},
+			"1": {"bingo.txt", "Bingo"},
+		},
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("Status code incorrect. Found %d, expected %d", recorder.Code, http.StatusOK)
+	}
+	if !strings.Contains(recorder.Body.String(), "total limit") {
+		t.Errorf("Total limit error not found. Body: %s", recorder.Body.String())
+	}
+}
+
+func TestUploadsWithoutMapPOST(t *testing.T) {
+	app := New(schema)
+	app.UploadsWithoutMap = true
+	router := setupRouter(app)
+
+	buff := bytes.NewBuffer(nil)
+	form := multipart.NewWriter(buff)
+	form.WriteField("query", `mutation uploadFile ($file: Upload!, $value: Int!) { singleFileAndValue(file: $file, value: $value) { file { filename size } value } }`)
+	form.WriteField("operationName", "uploadFile")
+	form.WriteField("variables", `{"value": 10}`)
+	w, _ := form.CreateFormFile("file", "hello.txt")
+	w.Write([]byte("Hello, World"))
+	form.Close()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", buff)
+	request.Header.Add("Content-Type", form.FormDataContentType())
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"singleFileAndValue":{"file":{"filename":"hello.txt","size":12},"value":10}}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+type countingStore struct {
+	puts int
+}
+
+func (s *countingStore) Put(ctx context.Context, file *multipart.FileHeader) (interface{}, error) {
+	s.puts++
+	return file, nil
+}
+
+func TestDeduplicateUploadsPOST(t *testing.T) {
+	store := &countingStore{}
+	app := New(schema)
+	app.UploadStore = store
+	app.DeduplicateUploads = true
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation uploadFile ( $files: [Upload!]! ) { multiUpload( files: $files ) { filename size } }", "variables": {"files": [null, null]}}`,
+		`{"0": ["variables.files.0"], "1": ["variables.files.1"]}`,
+		map[string][2]string{"0": {"a.txt", "Hello, World"}, "1": {"b.txt", "Hello, World"}},
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	var res struct {
+		Data struct {
+			Files []struct {
+				Filename string `json:"filename"`
+			} `json:"multiUpload"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+		t.Fatalf("Response unmarshal failed. Err: %v", err)
+	}
+	if store.puts != 1 {
+		t.Errorf("Identical files stored more than once. Found %d, expected %d", store.puts, 1)
+	}
+	if len(res.Data.Files) != 2 || res.Data.Files[0].Filename != res.Data.Files[1].Filename {
+		t.Errorf("Identical files resolved to different values. Body: %s", recorder.Body.String())
+	}
+}
+
+// Fails the first `failures` puts, then stores the content read from the file.
+type flakyStore struct {
+	failures int
+	puts     int
+	contents []string
+}
+
+func (s *flakyStore) Put(ctx context.Context, file *multipart.FileHeader) (interface{}, error) {
+	s.puts++
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	content, _ := ioutil.ReadAll(f)
+	if s.puts <= s.failures {
+		return nil, errors.New("storage backend unavailable")
+	}
+	s.contents = append(s.contents, string(content))
+	return file, nil
+}
+
+func TestUploadStoreRetriesPOST(t *testing.T) {
+	for _, tc := range []struct {
+		retries  int
+		expected string
+	}{
+		{1, `{"data":{"singleUpload":{"size":12}}}`},
+		{0, "could not store file upload"},
+	} {
+		store := &flakyStore{failures: 1}
+		app := New(schema)
+		app.UploadStore = store
+		app.UploadStoreRetries = tc.retries
+		app.UploadStoreRetryBackoff = time.Millisecond
+		router := setupRouter(app)
+
+		recorder := httptest.NewRecorder()
+		request := newUploadRequest(
+			`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+			`{"file": ["variables.file"]}`,
+			map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+		)
+
+		router.ServeHTTP(recorder, request)
+
+		if body := recorder.Body.String(); !strings.Contains(body, tc.expected) {
+			t.Errorf("Response with %d retries incorrect. Found %s, expected %s", tc.retries, body, tc.expected)
+		}
+		if store.puts != tc.retries+1 {
+			t.Errorf("Put attempts incorrect. Found %d, expected %d", store.puts, tc.retries+1)
+		}
+	}
+}
+
+func TestUploadStoreRetryRereadsFilePOST(t *testing.T) {
+	store := &flakyStore{failures: 2}
+	app := New(schema)
+	app.UploadStore = store
+	app.UploadStoreRetries = 2
+	router := setupRouter(app)
+
+	request := newUploadRequest(
+		`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+		`{"file": ["variables.file"]}`,
+		map[string][2]string{"file": {"hello.txt", "Hello, World"}},
+	)
+	router.ServeHTTP(httptest.NewRecorder(), request)
+
+	if len(store.contents) != 1 || store.contents[0] != "Hello, World" {
+		t.Errorf("Retried upload content incorrect. Found %q", store.contents)
+	}
+}
+
+func newUndersizedListUploadRequest() *http.Request {
+	return newUploadRequest(
+		`{"query": "mutation ($files: [Upload!]!) { multiUpload(files: $files) { filename } }", "variables": {"files": [null]}}`,
+		`{"0": ["variables.files.0"], "1": ["variables.files.1"]}`,
+		map[string][2]string{
+			"0": {"hello.txt", "Hello, World"},
+			"1": {"bingo.txt", "Bingo"},
+		},
+	)
+}
+
+func TestUploadListIndexOutOfRangePOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, newUndersizedListUploadRequest())
+
+	expected := "index 1 is out of range of variables.files with length 1"
+	if body := recorder.Body.String(); !strings.Contains(body, expected) {
+		t.Errorf("Error incorrect. Found %s, expected it to contain %s", body, expected)
+	}
+}
+
+func TestGrowUploadListsPOST(t *testing.T) {
+	app := New(schema)
+	app.GrowUploadLists = true
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, newUndersizedListUploadRequest())
+
+	expected := `{"data":{"multiUpload":[{"filename":"hello.txt"},{"filename":"bingo.txt"}]}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+var formValueSchema, _ = graphql.NewSchema(graphql.SchemaConfig{
+	Query: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"hello": helloQuery,
+		},
+	}),
+	Mutation: graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"join": &graphql.Field{
+				Type: graphql.String,
+				Args: graphql.FieldConfigArgument{
+					"a": &graphql.ArgumentConfig{Type: graphql.String},
+					"b": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return fmt.Sprintf("%v,%v", p.Args["a"], p.Args["b"]), nil
+				},
+			},
+		},
+	}),
+})
+
+func TestUploadFormValuesSharingValuePOST(t *testing.T) {
+	app := New(formValueSchema)
+	router := setupRouter(app)
+
+	buff := bytes.NewBuffer(nil)
+	form := multipart.NewWriter(buff)
+	form.WriteField("operations", `{"query": "mutation ($a: String, $b: String) { join(a: $a, b: $b) }", "variables": {"a": null, "b": null}}`)
+	form.WriteField("map", `{"first": ["variables.a"], "second": ["variables.b"]}`)
+	form.WriteField("first", "same")
+	form.WriteField("second", "same")
+	form.Close()
+
+	recorder := httptest.NewRecorder()
+	request, _ := http.NewRequest("POST", "/", buff)
+	request.Header.Add("Content-Type", form.FormDataContentType())
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"join":"same,same"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+}
+
+func TestUploadScalarRejectsNonFilesPOST(t *testing.T) {
+	app := New(schema)
+	router := setupRouter(app)
+
+	for _, body := range []string{
+		`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": "hello.txt"}}`,
+		`{"query": "mutation { singleUpload(file: \"hello.txt\") { size } }"}`,
+	} {
+		recorder := httptest.NewRecorder()
+		request, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+		request.Header.Add("Content-Type", "application/json")
+
+		router.ServeHTTP(recorder, request)
+
+		var res uploadErrorResponse
+		if err := json.Unmarshal(recorder.Body.Bytes(), &res); err != nil {
+			t.Errorf("Response unmarshal failed. Err: %v", err)
+		}
+		if len(res.Errors) != 1 || strings.Contains(recorder.Body.String(), `"size"`) {
+			t.Errorf("Non file upload value accepted. Body: %s", recorder.Body.String())
+		}
+	}
+}
+
+func TestGetUploadedFilesPOST(t *testing.T) {
+	var found []string
+	uploadsSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"hello": helloQuery,
+			},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"count": &graphql.Field{
+					Type: graphql.Int,
+					Args: graphql.FieldConfigArgument{
+						"file": &graphql.ArgumentConfig{Type: UploadType},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						files := GetUploadedFiles(p.Context)
+						for _, file := range files {
+							found = append(found, file.Filename)
+						}
+						return len(files), nil
+					},
+				},
+			},
+		}),
+	})
+	app := New(uploadsSchema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation ($a: Upload, $b: Upload) { a: count(file: $a) b: count(file: $b) }", "variables": {"a": null, "b": null}}`,
+		`{"0": ["variables.a"], "1": ["variables.b"]}`,
+		map[string][2]string{
+			"0": {"a.txt", "A"},
+			"1": {"b.txt", "B"},
+		},
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"a":2,"b":2}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+	if strings.Join(found, ",") != "a.txt,b.txt,a.txt,b.txt" {
+		t.Errorf("Uploaded files incorrect. Found %v, expected each resolver to find %v", found, []string{"a.txt", "b.txt"})
+	}
+}
+
+func TestUploadMultiplePathsPOST(t *testing.T) {
+	var a, b *multipart.FileHeader
+	pathsSchema, _ := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name:   "Query",
+			Fields: graphql.Fields{"hello": helloQuery},
+		}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Mutation",
+			Fields: graphql.Fields{
+				"compare": &graphql.Field{
+					Type: graphql.String,
+					Args: graphql.FieldConfigArgument{
+						"a":    &graphql.ArgumentConfig{Type: UploadType},
+						"list": &graphql.ArgumentConfig{Type: graphql.NewList(UploadType)},
+					},
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						a, _ = p.Args["a"].(*multipart.FileHeader)
+						list, _ := p.Args["list"].([]interface{})
+						if len(list) == 1 {
+							b, _ = list[0].(*multipart.FileHeader)
+						}
+						return a.Filename, nil
+					},
+				},
+			},
+		}),
+	})
+	app := New(pathsSchema)
+	router := setupRouter(app)
+
+	recorder := httptest.NewRecorder()
+	request := newUploadRequest(
+		`{"query": "mutation ($a: Upload, $list: [Upload]) { compare(a: $a, list: $list) }", "variables": {"a": null, "list": [null]}}`,
+		`{"0": ["variables.a", "variables.list.0"]}`,
+		map[string][2]string{"0": {"hello.txt", "Hello, World"}},
+	)
+
+	router.ServeHTTP(recorder, request)
+
+	expected := `{"data":{"compare":"hello.txt"}}`
+	if body := recorder.Body.String(); body != expected {
+		t.Errorf("Response incorrect. Found %s, expected %s", body, expected)
+	}
+	if a == nil || a != b {
+		t.Errorf("Uploaded file headers incorrect. Found %p and %p, expected the same header", a, b)
+	}
+}
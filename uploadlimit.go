@@ -0,0 +1,43 @@
+package graphqlgin
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// Function to derive the key concurrent uploads are limited by from a request
+type UploadLimitKeyFn func(c *gin.Context) string
+
+// Returns the key concurrent uploads of the request are limited by, the client
+// IP unless `UploadLimitKey` is set.
+func (app *GraphQLApp) uploadLimitKey(c *gin.Context) string {
+	if app.UploadLimitKey != nil {
+		return app.UploadLimitKey(c)
+	}
+	return c.ClientIP()
+}
+
+// Reserves an upload slot for `key`. Returns false when `key` already has
+// `MaxConcurrentUploads` uploads in progress.
+func (app *GraphQLApp) acquireUploadSlot(key string) bool {
+	app.uploadsMu.Lock()
+	defer app.uploadsMu.Unlock()
+	if app.uploadsInProgress == nil {
+		app.uploadsInProgress = map[string]int{}
+	}
+	if app.uploadsInProgress[key] >= app.MaxConcurrentUploads {
+		return false
+	}
+	app.uploadsInProgress[key]++
+	return true
+}
+
+// Frees an upload slot of `key`.
+func (app *GraphQLApp) releaseUploadSlot(key string) {
+	app.uploadsMu.Lock()
+	defer app.uploadsMu.Unlock()
+	if app.uploadsInProgress[key] <= 1 {
+		delete(app.uploadsInProgress, key)
+	} else {
+		app.uploadsInProgress[key]--
+	}
+}
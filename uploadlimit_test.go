@@ -0,0 +1,72 @@
+package graphqlgin
+
+import (
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Blocks puts of files named `hold.txt` until released, signalling when such
+// a put has started.
+type blockingStore struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingStore) Put(ctx context.Context, file *multipart.FileHeader) (interface{}, error) {
+	if file.Filename == "hold.txt" {
+		s.started <- struct{}{}
+		<-s.release
+	}
+	return file, nil
+}
+
+func TestMaxConcurrentUploadsPOST(t *testing.T) {
+	store := &blockingStore{started: make(chan struct{}), release: make(chan struct{})}
+	app := New(schema)
+	app.UploadStore = store
+	app.MaxConcurrentUploads = 1
+	app.UploadLimitKey = func(c *gin.Context) string {
+		return c.GetHeader("X-Client")
+	}
+	router := setupRouter(app)
+
+	upload := func(client string, filename string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request := newUploadRequest(
+			`{"query": "mutation ($file: Upload!) { singleUpload(file: $file) { size } }", "variables": {"file": null}}`,
+			`{"file": ["variables.file"]}`,
+			map[string][2]string{"file": {filename, "Hello, World"}},
+		)
+		request.Header.Add("X-Client", client)
+		router.ServeHTTP(recorder, request)
+		return recorder
+	}
+	expected := `{"data":{"singleUpload":{"size":12}}}`
+
+	// hold an upload of client a in progress
+	done := make(chan *httptest.ResponseRecorder, 1)
+	go func() {
+		done <- upload("a", "hold.txt")
+	}()
+	<-store.started
+
+	if recorder := upload("a", "hello.txt"); recorder.Code != http.StatusTooManyRequests {
+		t.Errorf("Status code of excess upload incorrect. Found %d, expected %d", recorder.Code, http.StatusTooManyRequests)
+	}
+	if recorder := upload("b", "hello.txt"); recorder.Body.String() != expected {
+		t.Errorf("Upload of other client incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+
+	close(store.release)
+	if recorder := <-done; recorder.Body.String() != expected {
+		t.Errorf("Held upload incorrect. Found %s, expected %s", recorder.Body.String(), expected)
+	}
+	if recorder := upload("a", "hello.txt"); recorder.Code != http.StatusOK {
+		t.Errorf("Status code after release incorrect. Found %d, expected %d", recorder.Code, http.StatusOK)
+	}
+}